@@ -41,6 +41,11 @@ hooks.beforeEach(function (transaction) {
         transaction.skip = false
         transaction.request.body = "wrong_json"
     }
+    // the feedback and history endpoints are disabled without their stores, the content read API is not running
+    if (transaction.name.startsWith("Feedback >") || transaction.name.startsWith("History >") || transaction.name.startsWith("Stored content >")) {
+        hooks.log("skipping: " + transaction.name);
+        transaction.skip = true;
+    }
     if (transaction.name.startsWith("Health > /__gtg")) {
         hooks.log("skipping: " + transaction.name);
         transaction.skip = true;
@@ -0,0 +1,128 @@
+// Package audit records what was suggested for which content, as NDJSON, so the suggestions quality can be
+// evaluated offline.
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/service"
+)
+
+// Redacted replaces the values of the redacted fields of the payload.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactedFields keeps the body text of the content out of the audit log.
+var DefaultRedactedFields = []string{"body", "bodyXML", "bodyText"}
+
+// Record is a single line of the audit log.
+type Record struct {
+	Time          time.Time                    `json:"time"`
+	TransactionID string                       `json:"transactionId"`
+	Origin        string                       `json:"origin,omitempty"`
+	ContentID     string                       `json:"contentId,omitempty"`
+	Experiment    string                       `json:"experiment,omitempty"`
+	Variant       string                       `json:"variant,omitempty"`
+	Payload       map[string]interface{}       `json:"payload,omitempty"`
+	Sources       []service.SourceReport       `json:"sources"`
+	Dropped       []service.DroppedSuggestion  `json:"dropped"`
+	Response      *service.SuggestionsResponse `json:"response,omitempty"`
+	Error         string                       `json:"error,omitempty"`
+}
+
+// Auditor writes the audit records to a Sink after redacting the payload.
+type Auditor struct {
+	sink         Sink
+	redactFields [][]string
+	log          *logger.UPPLogger
+	mu           sync.Mutex
+}
+
+// New creates an Auditor. redactFields are payload field names, nested fields are separated by dots,
+// e.g. "alternativeTitles.promotionalTitle". The "*" field drops the whole payload.
+func New(sink Sink, redactFields []string, log *logger.UPPLogger) *Auditor {
+	a := &Auditor{sink: sink, log: log}
+	for _, f := range redactFields {
+		if f = strings.TrimSpace(f); f != "" {
+			a.redactFields = append(a.redactFields, strings.Split(f, "."))
+		}
+	}
+	return a
+}
+
+// Audit completes the record with the time, the content ID and the redacted payload and writes it to the sink.
+// Failures are logged, they never affect the request.
+func (a *Auditor) Audit(record Record, payload []byte) {
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	if record.Sources == nil {
+		record.Sources = []service.SourceReport{}
+	}
+	if record.Dropped == nil {
+		record.Dropped = []service.DroppedSuggestion{}
+	}
+
+	var content map[string]interface{}
+	if err := json.Unmarshal(payload, &content); err == nil {
+		if record.ContentID == "" {
+			record.ContentID = contentID(content)
+		}
+		record.Payload = a.redact(content)
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		a.log.WithTransactionID(record.TransactionID).WithError(err).Error("Could not marshal audit record")
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.sink.Write(append(line, '\n')); err != nil {
+		a.log.WithTransactionID(record.TransactionID).WithError(err).Error("Could not write audit record")
+	}
+}
+
+// Close closes the underlying sink.
+func (a *Auditor) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.sink.Close()
+}
+
+func (a *Auditor) redact(content map[string]interface{}) map[string]interface{} {
+	for _, path := range a.redactFields {
+		if len(path) == 1 && path[0] == "*" {
+			return nil
+		}
+		redactPath(content, path)
+	}
+	return content
+}
+
+func redactPath(content map[string]interface{}, path []string) {
+	value, ok := content[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		content[path[0]] = Redacted
+		return
+	}
+	if nested, ok := value.(map[string]interface{}); ok {
+		redactPath(nested, path[1:])
+	}
+}
+
+func contentID(content map[string]interface{}) string {
+	for _, field := range []string{"id", "uuid"} {
+		if id, ok := content[field].(string); ok && id != "" {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingSink struct{}
+
+func (failingSink) Write([]byte) error { return errors.New("disk full") }
+func (failingSink) Close() error       { return nil }
+
+func TestAuditor_Audit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	auditor := New(NewWriterSink(buf), append(DefaultRedactedFields, "alternativeTitles.promotionalTitle"), logger.NewUPPLogger("test", "panic"))
+
+	suggestion := service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/1", Type: "http://www.ft.com/ontology/Topic"}}
+	auditor.Audit(Record{
+		TransactionID: "tid_test",
+		Origin:        "tests_origin",
+		Sources:       []service.SourceReport{{Source: "Ontotext Suggestion API", Suggestions: []service.Suggestion{suggestion}}},
+		Dropped:       []service.DroppedSuggestion{{Suggestion: suggestion, Source: "Ontotext Suggestion API", Filter: service.FilterBlacklist}},
+		Response:      &service.SuggestionsResponse{Suggestions: []service.Suggestion{}},
+	}, []byte(`{"id":"http://www.ft.com/thing/content","title":"Title","bodyXML":"<body>secret</body>","alternativeTitles":{"promotionalTitle":"Promo"}}`))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 1)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[0], &record))
+	assert.Equal(t, "tid_test", record["transactionId"])
+	assert.Equal(t, "tests_origin", record["origin"])
+	assert.Equal(t, "http://www.ft.com/thing/content", record["contentId"])
+	assert.NotEmpty(t, record["time"])
+
+	payload := record["payload"].(map[string]interface{})
+	assert.Equal(t, "Title", payload["title"])
+	assert.Equal(t, Redacted, payload["bodyXML"])
+	assert.Equal(t, Redacted, payload["alternativeTitles"].(map[string]interface{})["promotionalTitle"])
+	assert.NotContains(t, string(lines[0]), "secret")
+
+	dropped := record["dropped"].([]interface{})
+	require.Len(t, dropped, 1)
+	assert.Equal(t, "blacklist", dropped[0].(map[string]interface{})["filter"])
+	assert.Len(t, record["sources"].([]interface{}), 1)
+}
+
+func TestAuditor_AuditWithoutPayload(t *testing.T) {
+	buf := &bytes.Buffer{}
+	auditor := New(NewWriterSink(buf), []string{"*"}, logger.NewUPPLogger("test", "panic"))
+
+	auditor.Audit(Record{TransactionID: "tid_test", Error: "aggregating suggestions failed"}, []byte(`{"uuid":"content-uuid","body":"text"}`))
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "content-uuid", record["contentId"])
+	assert.NotContains(t, record, "payload")
+	assert.Equal(t, []interface{}{}, record["sources"])
+	assert.Equal(t, []interface{}{}, record["dropped"])
+	assert.Equal(t, "aggregating suggestions failed", record["error"])
+}
+
+func TestAuditor_AuditSinkFailureIsNotFatal(t *testing.T) {
+	auditor := New(failingSink{}, nil, logger.NewUPPLogger("test", "panic"))
+	assert.NotPanics(t, func() {
+		auditor.Audit(Record{TransactionID: "tid_test"}, []byte(`{}`))
+	})
+}
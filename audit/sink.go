@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	SinkNone   = "none"
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+)
+
+// Sink receives the NDJSON lines of the audit log.
+type Sink interface {
+	Write(line []byte) error
+	Close() error
+}
+
+// WriterSink writes the records to an io.Writer, e.g. os.Stdout. Closing it does not close the writer.
+type WriterSink struct {
+	w io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Write(line []byte) error {
+	_, err := s.w.Write(line)
+	return err
+}
+
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// RotatingFileSink appends the records to a file and rotates it once it exceeds maxBytes.
+// Rotated files are renamed path.1, path.2, ... up to maxBackups, older ones are removed.
+type RotatingFileSink struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func NewRotatingFileSink(path string, maxBytes int64, maxBackups int) (*RotatingFileSink, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("audit file max size must be positive, got %d", maxBytes)
+	}
+	if maxBackups < 0 {
+		return nil, fmt.Errorf("audit file max backups must not be negative, got %d", maxBackups)
+	}
+	s := &RotatingFileSink{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *RotatingFileSink) Write(line []byte) error {
+	if s.size > 0 && s.size+int64(len(line)) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+func (s *RotatingFileSink) Close() error {
+	return s.file.Close()
+}
+
+func (s *RotatingFileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+func (s *RotatingFileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if s.maxBackups == 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return s.open()
+	}
+	if err := os.Remove(s.backup(s.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(s.backup(i), s.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, s.backup(1)); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *RotatingFileSink) backup(i int) string {
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	sink, err := NewRotatingFileSink(path, 10, 2)
+	require.NoError(t, err)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		require.NoError(t, sink.Write([]byte(line)))
+	}
+	require.NoError(t, sink.Close())
+
+	assertFile(t, path, "fourth\n")
+	assertFile(t, path+".1", "third\n")
+	assertFile(t, path+".2", "second\n")
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err), "only maxBackups files are kept")
+}
+
+func TestRotatingFileSink_NoBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	sink, err := NewRotatingFileSink(path, 8, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write([]byte("first\n")))
+	require.NoError(t, sink.Write([]byte("second\n")))
+	require.NoError(t, sink.Close())
+
+	assertFile(t, path, "second\n")
+	_, err = os.Stat(path + ".1")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRotatingFileSink_AppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0644))
+
+	sink, err := NewRotatingFileSink(path, 100, 1)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write([]byte("new\n")))
+	require.NoError(t, sink.Close())
+
+	assertFile(t, path, "old\nnew\n")
+}
+
+func TestNewRotatingFileSink_InvalidSettings(t *testing.T) {
+	_, err := NewRotatingFileSink(filepath.Join(t.TempDir(), "audit.ndjson"), 0, 1)
+	assert.Error(t, err)
+	_, err = NewRotatingFileSink(filepath.Join(t.TempDir(), "audit.ndjson"), 10, -1)
+	assert.Error(t, err)
+}
+
+func assertFile(t *testing.T, path, expected string) {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
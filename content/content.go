@@ -0,0 +1,193 @@
+// Package content is the typed model of the content payloads and their validation.
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DefaultTextFields are the fields the suggesters find concepts in.
+var DefaultTextFields = []string{"title", "alternativeTitles.promotionalTitle", "byline", "standfirst", "bodyXML", "body"}
+
+// Content is the part of a content payload the suggestions are made from, the payload can have other fields.
+type Content struct {
+	ID                string            `json:"id"`
+	UUID              string            `json:"uuid"`
+	Type              string            `json:"type"`
+	Title             string            `json:"title"`
+	AlternativeTitles AlternativeTitles `json:"alternativeTitles"`
+	Byline            string            `json:"byline"`
+	Standfirst        string            `json:"standfirst"`
+	Body              string            `json:"body"`
+	BodyXML           string            `json:"bodyXML"`
+	Brands            []Brand           `json:"brands"`
+	Identifiers       []Identifier      `json:"identifiers"`
+	PublishedDate     string            `json:"publishedDate"`
+}
+
+type AlternativeTitles struct {
+	PromotionalTitle string `json:"promotionalTitle"`
+}
+
+type Brand struct {
+	ID string `json:"id"`
+}
+
+type Identifier struct {
+	Authority       string `json:"authority"`
+	IdentifierValue string `json:"identifierValue"`
+}
+
+// stringFields are the fields the rules can name, nested fields are separated by dots.
+var stringFields = map[string]func(Content) string{
+	"id":                                 func(c Content) string { return c.ID },
+	"uuid":                               func(c Content) string { return c.UUID },
+	"type":                               func(c Content) string { return c.Type },
+	"title":                              func(c Content) string { return c.Title },
+	"alternativeTitles.promotionalTitle": func(c Content) string { return c.AlternativeTitles.PromotionalTitle },
+	"byline":                             func(c Content) string { return c.Byline },
+	"standfirst":                         func(c Content) string { return c.Standfirst },
+	"body":                               func(c Content) string { return c.Body },
+	"bodyXML":                            func(c Content) string { return c.BodyXML },
+	"publishedDate":                      func(c Content) string { return c.PublishedDate },
+}
+
+// FieldError is the reason a field of the payload is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the invalid fields of a payload.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Field + " " + fe.Message
+	}
+	return "invalid content: " + strings.Join(msgs, ", ")
+}
+
+// Rules are what a payload needs to be suggested for.
+type Rules struct {
+	// TextFields are the fields at least one of which should have some text.
+	TextFields []string
+	// RequiredFields are the fields that should not be empty.
+	RequiredFields []string
+}
+
+// Validator parses the payloads and checks them against its rules.
+type Validator struct {
+	rules Rules
+}
+
+// NewValidator checks the rules only name known fields.
+func NewValidator(rules Rules) (*Validator, error) {
+	for _, fields := range [][]string{rules.TextFields, rules.RequiredFields} {
+		for _, field := range fields {
+			if _, ok := stringFields[field]; !ok {
+				return nil, fmt.Errorf("unknown content field %q, the fields are %s", field, strings.Join(knownFields(), ", "))
+			}
+		}
+	}
+	return &Validator{rules: rules}, nil
+}
+
+func knownFields() []string {
+	fields := make([]string, 0, len(stringFields))
+	for field := range stringFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Parse returns the content of the JSON object payload, or a ValidationError with every invalid field.
+func (v *Validator) Parse(payload []byte) (Content, error) {
+	var c Content
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return c, err
+	}
+
+	var errs []FieldError
+	value := reflect.ValueOf(&c).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		field, ok := raw[name]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(field, value.Field(i).Addr().Interface()); err != nil {
+			errs = append(errs, typeError(name, err))
+		}
+	}
+
+	for _, field := range v.rules.RequiredFields {
+		if strings.TrimSpace(stringFields[field](c)) == "" && !hasError(errs, field) {
+			errs = append(errs, FieldError{Field: field, Message: "is required"})
+		}
+	}
+	if len(v.rules.TextFields) > 0 && !v.hasText(c) {
+		message := "has no text, the content needs one of " + strings.Join(v.rules.TextFields, ", ")
+		for _, field := range v.rules.TextFields {
+			if !hasError(errs, field) {
+				errs = append(errs, FieldError{Field: field, Message: message})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return c, &ValidationError{Errors: errs}
+	}
+	return c, nil
+}
+
+func (v *Validator) hasText(c Content) bool {
+	for _, field := range v.rules.TextFields {
+		if strings.TrimSpace(stringFields[field](c)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func typeError(name string, err error) FieldError {
+	typeErr, ok := err.(*json.UnmarshalTypeError)
+	if !ok {
+		return FieldError{Field: name, Message: "is invalid"}
+	}
+	field := name
+	if typeErr.Field != "" {
+		field += "." + typeErr.Field
+	}
+	return FieldError{Field: field, Message: "should be " + jsonType(typeErr.Type) + ", not " + typeErr.Value}
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Slice:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	default:
+		return "a " + t.Kind().String()
+	}
+}
+
+func hasError(errs []FieldError, field string) bool {
+	for _, e := range errs {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
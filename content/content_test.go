@@ -0,0 +1,91 @@
+package content
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewValidator(t *testing.T) {
+	_, err := NewValidator(Rules{TextFields: DefaultTextFields, RequiredFields: []string{"id"}})
+	assert.NoError(t, err)
+	_, err = NewValidator(Rules{RequiredFields: []string{"brands"}})
+	assert.EqualError(t, err, `unknown content field "brands", the fields are alternativeTitles.promotionalTitle, body, bodyXML, byline, id, publishedDate, standfirst, title, type, uuid`)
+}
+
+func TestValidator_Parse(t *testing.T) {
+	v, err := NewValidator(Rules{TextFields: DefaultTextFields})
+	require.NoError(t, err)
+
+	c, err := v.Parse([]byte(`{"id":"http://www.ft.com/thing/9d5e441e","title":"Wall Street","alternativeTitles":{"promotionalTitle":"Stocks"},` +
+		`"brands":[{"id":"http://api.ft.com/things/dbb0bdae"}],"other":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, Content{
+		ID:                "http://www.ft.com/thing/9d5e441e",
+		Title:             "Wall Street",
+		AlternativeTitles: AlternativeTitles{PromotionalTitle: "Stocks"},
+		Brands:            []Brand{{ID: "http://api.ft.com/things/dbb0bdae"}},
+	}, c)
+
+	_, err = v.Parse([]byte(`{"alternativeTitles":{"promotionalTitle":" "},"bodyXML":"<body>text</body>"}`))
+	assert.NoError(t, err, "one text field is enough")
+
+	_, err = v.Parse([]byte(`not json`))
+	var validationErr *ValidationError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &validationErr))
+}
+
+func TestValidator_ParseErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rules    Rules
+		payload  string
+		expected []FieldError
+	}{
+		{
+			name:    "no text",
+			rules:   Rules{TextFields: []string{"title", "bodyXML"}},
+			payload: `{"foo":1,"title":"  "}`,
+			expected: []FieldError{
+				{Field: "title", Message: "has no text, the content needs one of title, bodyXML"},
+				{Field: "bodyXML", Message: "has no text, the content needs one of title, bodyXML"},
+			},
+		},
+		{
+			name:    "wrong types",
+			rules:   Rules{TextFields: []string{"title", "bodyXML"}},
+			payload: `{"title":1,"brands":{"id":"brand"},"alternativeTitles":{"promotionalTitle":false},"bodyXML":"text"}`,
+			expected: []FieldError{
+				{Field: "title", Message: "should be a string, not number"},
+				{Field: "alternativeTitles.promotionalTitle", Message: "should be a string, not bool"},
+				{Field: "brands", Message: "should be an array, not object"},
+			},
+		},
+		{
+			name:    "required",
+			rules:   Rules{RequiredFields: []string{"id", "type"}},
+			payload: `{"id":"","type":"Article"}`,
+			expected: []FieldError{
+				{Field: "id", Message: "is required"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewValidator(tc.rules)
+			require.NoError(t, err)
+			_, err = v.Parse([]byte(tc.payload))
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tc.expected, validationErr.Errors)
+		})
+	}
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := &ValidationError{Errors: []FieldError{{Field: "title", Message: "is required"}, {Field: "id", Message: "is required"}}}
+	assert.EqualError(t, err, "invalid content: title is required, id is required")
+}
@@ -0,0 +1,209 @@
+package content
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	paragraphSeparator = regexp.MustCompile(`\n\s*\n`)
+	// scriptRegexp matches the scripts and styles, their text is not markup and the decoder cannot read it.
+	scriptRegexp = regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>`)
+)
+
+// xmlContent is a content in XML, its elements are named as the JSON fields.
+type xmlContent struct {
+	ID                string `xml:"id"`
+	UUID              string `xml:"uuid"`
+	Type              string `xml:"type"`
+	Title             string `xml:"title"`
+	AlternativeTitles struct {
+		PromotionalTitle string `xml:"promotionalTitle"`
+	} `xml:"alternativeTitles"`
+	Byline        string   `xml:"byline"`
+	Standfirst    string   `xml:"standfirst"`
+	Body          innerXML `xml:"body"`
+	BodyXML       innerXML `xml:"bodyXML"`
+	PublishedDate string   `xml:"publishedDate"`
+}
+
+type innerXML struct {
+	Markup string `xml:",innerxml"`
+}
+
+// FromText returns the JSON payload of a plain text, its paragraphs are separated by blank lines.
+func FromText(text []byte) ([]byte, error) {
+	if !utf8.Valid(text) {
+		return nil, errors.New("the text is not UTF-8")
+	}
+	var body strings.Builder
+	for _, paragraph := range paragraphSeparator.Split(string(text), -1) {
+		if paragraph = collapseSpaces(paragraph); paragraph != "" {
+			body.WriteString("<p>")
+			xml.EscapeText(&body, []byte(paragraph))
+			body.WriteString("</p>")
+		}
+	}
+	if body.Len() == 0 {
+		return nil, errors.New("the text is empty")
+	}
+	return payload(Content{BodyXML: "<body>" + body.String() + "</body>"})
+}
+
+// FromHTML returns the JSON payload of an HTML document or fragment. The title is the one of the document,
+// or its first h1 heading, and the body is the markup of the body element, or of the whole fragment,
+// without the embedded content, images, scripts and comments.
+func FromHTML(document []byte) ([]byte, error) {
+	document = scriptRegexp.ReplaceAll(document, nil)
+	decoder := newHTMLDecoder(bytes.NewReader(document))
+	var title, heading strings.Builder
+	var collecting *strings.Builder
+	bodyStart := -1
+	headingSeen := false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			// the end of the document, or markup too broken to look further
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "body" && bodyStart < 0:
+				bodyStart = int(decoder.InputOffset())
+			case t.Name.Local == "title" && bodyStart < 0:
+				collecting = &title
+			case t.Name.Local == "h1" && !headingSeen:
+				headingSeen = true
+				collecting = &heading
+			}
+		case xml.EndElement:
+			if t.Name.Local == "title" || t.Name.Local == "h1" {
+				collecting = nil
+			}
+		case xml.CharData:
+			if collecting != nil {
+				collecting.Write(t)
+			}
+		}
+	}
+
+	fragment := document
+	if bodyStart >= 0 {
+		// the decoder reports the end of the body after the elements it closes, look for it instead
+		bodyEnd := bytes.LastIndex(bytes.ToLower(document), []byte("</body"))
+		if bodyEnd < bodyStart {
+			bodyEnd = len(document)
+		}
+		fragment = document[bodyStart:bodyEnd]
+	}
+	// the end of the body closes the elements left open
+	body, _, err := rewriteMarkup("<body>"+string(fragment)+"</body>", true, false, 0)
+	if err != nil {
+		// not well formed enough to be rewritten, keep its text
+		var text strings.Builder
+		xml.EscapeText(&text, []byte(collapseSpaces(html.UnescapeString(tagRegexp.ReplaceAllString(string(fragment), " ")))))
+		body = "<body><p>" + text.String() + "</p></body>"
+	}
+
+	c := Content{Title: collapseSpaces(title.String())}
+	if c.Title == "" {
+		c.Title = collapseSpaces(heading.String())
+	}
+	if text, _, _ := rewriteMarkup(body, true, true, 0); text != "" {
+		c.BodyXML = body
+	}
+	return payload(c)
+}
+
+// FromXML returns the JSON payload of an XML document, either a body, as in the bodyXML field, or a content
+// whose elements are named as the JSON fields.
+func FromXML(document []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, errors.New("the document has no root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == "body" {
+			// checks the body is well formed
+			if err := decoder.Skip(); err != nil {
+				return nil, err
+			}
+			return payload(Content{BodyXML: strings.TrimSpace(string(document[offset:decoder.InputOffset()]))})
+		}
+		var x xmlContent
+		if err := decoder.DecodeElement(&x, &start); err != nil {
+			return nil, err
+		}
+		return payload(Content{
+			ID:                strings.TrimSpace(x.ID),
+			UUID:              strings.TrimSpace(x.UUID),
+			Type:              strings.TrimSpace(x.Type),
+			Title:             strings.TrimSpace(x.Title),
+			AlternativeTitles: AlternativeTitles{PromotionalTitle: strings.TrimSpace(x.AlternativeTitles.PromotionalTitle)},
+			Byline:            strings.TrimSpace(x.Byline),
+			Standfirst:        strings.TrimSpace(x.Standfirst),
+			Body:              strings.TrimSpace(x.Body.Markup),
+			BodyXML:           strings.TrimSpace(x.BodyXML.Markup),
+			PublishedDate:     strings.TrimSpace(x.PublishedDate),
+		})
+	}
+}
+
+func newHTMLDecoder(r io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	return decoder
+}
+
+// payload returns the JSON object with the non empty string fields of the content.
+func payload(c Content) ([]byte, error) {
+	fields := map[string]interface{}{}
+	for name, value := range stringFields {
+		v := value(c)
+		if v == "" {
+			continue
+		}
+		path := strings.Split(name, ".")
+		object := fields
+		for _, key := range path[:len(path)-1] {
+			nested, ok := object[key].(map[string]interface{})
+			if !ok {
+				nested = map[string]interface{}{}
+				object[key] = nested
+			}
+			object = nested
+		}
+		object[path[len(path)-1]] = v
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("the document has no text")
+	}
+	// the markup of the bodies is not escaped
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(fields); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,86 @@
+package content
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromText(t *testing.T) {
+	payload, err := FromText([]byte("US stocks see-sawed\r\nin early trading.\r\n\r\n  \nVolatility & calm <again>.\n"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bodyXML":"<body><p>US stocks see-sawed in early trading.</p><p>Volatility &amp; calm &lt;again&gt;.</p></body>"}`, string(payload))
+
+	_, err = FromText([]byte(" \n\n "))
+	assert.EqualError(t, err, "the text is empty")
+	_, err = FromText([]byte{0xff, 0xfe})
+	assert.EqualError(t, err, "the text is not UTF-8")
+}
+
+func TestFromHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		document string
+		expected string
+	}{
+		{
+			name: "document",
+			document: `<!DOCTYPE html><html><head><title>Wall Street  stocks</title><meta charset="utf-8"><script>var x = 1 < 2;</script></head>` +
+				`<body><h1>Heading</h1><img src="a.png"><p>US stocks&nbsp;see-sawed<br>on Tuesday.<p>Volatility &amp; calm.</body></html>`,
+			expected: `{"title":"Wall Street stocks","bodyXML":"<body><h1>Heading</h1><p>US stocks see-sawed<br/>on Tuesday.<p>Volatility &amp; calm.</p></p></body>"}`,
+		},
+		{
+			name:     "fragment",
+			document: `<h1>Wall Street stocks</h1><p>US stocks see-sawed.</p><h1>Other</h1>`,
+			expected: `{"title":"Wall Street stocks","bodyXML":"<body><h1>Wall Street stocks</h1><p>US stocks see-sawed.</p><h1>Other</h1></body>"}`,
+		},
+		{
+			name:     "unclosed body",
+			document: `<html><body><p>US stocks see-sawed.</p>`,
+			expected: `{"bodyXML":"<body><p>US stocks see-sawed.</p></body>"}`,
+		},
+		{
+			name:     "unclosed elements",
+			document: `<p>US stocks <b>see-sawed & more`,
+			expected: `{"bodyXML":"<body><p>US stocks <b>see-sawed &amp; more</b></p></body>"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := FromHTML([]byte(tc.document))
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(payload))
+		})
+	}
+
+	_, err := FromHTML([]byte(`<html><head><title> </title></head><body><script>alert(1)</script></body></html>`))
+	assert.EqualError(t, err, "the document has no text")
+}
+
+func TestFromXML(t *testing.T) {
+	payload, err := FromXML([]byte(`<?xml version="1.0"?>
+<content>
+  <id>http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09</id>
+  <title>Wall Street stocks</title>
+  <alternativeTitles><promotionalTitle>Wall Street volatile</promotionalTitle></alternativeTitles>
+  <byline>Eric Platt</byline>
+  <bodyXML><body><p>US stocks see-sawed &amp; more.</p></body></bodyXML>
+  <mainImage>c0cc4ca2</mainImage>
+</content>`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09","title":"Wall Street stocks",`+
+		`"alternativeTitles":{"promotionalTitle":"Wall Street volatile"},"byline":"Eric Platt",`+
+		`"bodyXML":"<body><p>US stocks see-sawed &amp; more.</p></body>"}`, string(payload))
+
+	payload, err = FromXML([]byte(`<?xml version="1.0"?><body><p>US stocks see-sawed.</p></body>`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bodyXML":"<body><p>US stocks see-sawed.</p></body>"}`, string(payload))
+
+	_, err = FromXML([]byte(`<body><p>US stocks see-sawed.</body>`))
+	assert.EqualError(t, err, "XML syntax error on line 1: element <p> closed by </body>")
+	_, err = FromXML([]byte(`<?xml version="1.0"?>`))
+	assert.EqualError(t, err, "the document has no root element")
+	_, err = FromXML([]byte(`<content><mainImage>c0cc4ca2</mainImage></content>`))
+	assert.EqualError(t, err, "the document has no text")
+}
@@ -0,0 +1,369 @@
+package content
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MarkupKeep leaves the markup of the body fields as it is.
+	MarkupKeep = "keep"
+	// MarkupNormalise drops the embedded content, images, scripts and comments of the body fields and collapses their whitespace.
+	MarkupNormalise = "normalise"
+	// MarkupStrip keeps the text of the body fields only.
+	MarkupStrip = "strip"
+
+	TransformationNormalised = "normalised"
+	TransformationStripped   = "stripped"
+	TransformationTruncated  = "truncated"
+)
+
+// markupFields are the body fields with markup, bodyText is plain text and only truncated.
+var markupFields = map[string]bool{"bodyXML": true, "body": true}
+
+var bodyFields = []string{"bodyXML", "body", "bodyText"}
+
+// droppedElements are not part of the text of the content.
+var droppedElements = map[string]bool{
+	"content":    true,
+	"ft-content": true,
+	"ft-related": true,
+	"head":       true,
+	"img":        true,
+	"script":     true,
+	"style":      true,
+}
+
+// blockElements separate the words of the text around them when the markup is stripped.
+var blockElements = map[string]bool{
+	"body": true, "p": true, "div": true, "br": true, "li": true, "ul": true, "ol": true, "blockquote": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "table": true, "tr": true, "td": true, "th": true,
+}
+
+var tagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+// Transformation is a change made to a field of the payload before it is sent to the suggesters.
+type Transformation struct {
+	Field          string `json:"field"`
+	Transformation string `json:"transformation"`
+	// OriginalLength and Length are the number of characters of the field before and after the transformation.
+	OriginalLength int `json:"originalLength"`
+	Length         int `json:"length"`
+}
+
+// Normaliser rewrites the body fields of the payloads, bodyXML, body and bodyText.
+type Normaliser struct {
+	markup        string
+	maxBodyLength int
+}
+
+// NewNormaliser rewrites the markup of the bodies as set by markup and truncates the bodies with more than maxBodyLength
+// characters of text, 0 for no limit.
+func NewNormaliser(markup string, maxBodyLength int) (*Normaliser, error) {
+	switch markup {
+	case MarkupKeep, MarkupNormalise, MarkupStrip:
+	default:
+		return nil, fmt.Errorf("unknown markup mode %q, only %s, %s and %s are supported", markup, MarkupKeep, MarkupNormalise, MarkupStrip)
+	}
+	if maxBodyLength < 0 {
+		return nil, fmt.Errorf("the maximum body length %d is negative", maxBodyLength)
+	}
+	return &Normaliser{markup: markup, maxBodyLength: maxBodyLength}, nil
+}
+
+// Normalise returns the payload with its body fields rewritten, and what was changed. The payload is returned as it
+// is when nothing is changed.
+func (n *Normaliser) Normalise(payload []byte) ([]byte, []Transformation, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return payload, nil, err
+	}
+
+	var transformations []Transformation
+	for _, field := range bodyFields {
+		raw, ok := fields[field]
+		if !ok {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			// not a string, the validation reports it
+			continue
+		}
+		rewritten, changes := n.rewrite(field, value)
+		if len(changes) == 0 {
+			continue
+		}
+		transformations = append(transformations, changes...)
+		fields[field], _ = json.Marshal(rewritten)
+	}
+	if len(transformations) == 0 {
+		return payload, nil, nil
+	}
+	normalised, err := json.Marshal(fields)
+	if err != nil {
+		return payload, nil, err
+	}
+	return normalised, transformations, nil
+}
+
+func (n *Normaliser) rewrite(field, value string) (string, []Transformation) {
+	var changes []Transformation
+	length := utf8.RuneCountInString(value)
+
+	markup := n.markup
+	if !markupFields[field] {
+		markup = MarkupStrip
+	} else if markup != MarkupKeep {
+		rewritten, _, err := rewriteMarkup(value, true, markup == MarkupStrip, 0)
+		if err != nil && markup == MarkupStrip {
+			rewritten, err = collapseSpaces(tagRegexp.ReplaceAllString(value, " ")), nil
+		}
+		if err == nil && rewritten != value {
+			transformation := TransformationNormalised
+			if markup == MarkupStrip {
+				transformation = TransformationStripped
+			}
+			changes = append(changes, Transformation{Field: field, Transformation: transformation, OriginalLength: length, Length: utf8.RuneCountInString(rewritten)})
+			value, length = rewritten, utf8.RuneCountInString(rewritten)
+		}
+	}
+
+	if n.maxBodyLength == 0 || length <= n.maxBodyLength {
+		return value, changes
+	}
+	var truncated string
+	cut := true
+	if markup == MarkupStrip {
+		truncated = truncateText(value, n.maxBodyLength)
+	} else {
+		var err error
+		truncated, cut, err = rewriteMarkup(value, false, false, n.maxBodyLength)
+		if err != nil {
+			// not well formed enough to be rewritten, cut it as text
+			truncated, cut = truncateText(value, n.maxBodyLength), true
+		}
+	}
+	if cut {
+		changes = append(changes, Transformation{Field: field, Transformation: TransformationTruncated, OriginalLength: length, Length: utf8.RuneCountInString(truncated)})
+		value = truncated
+	}
+	return value, changes
+}
+
+// rewriteMarkup returns the markup without the dropped elements, the comments and the runs of whitespace when clean is set,
+// or only its text when strip is set. When maxText is not 0 the markup is cut after maxText characters of text
+// and the open elements are closed, the second result tells if it was cut.
+func rewriteMarkup(markup string, clean, strip bool, maxText int) (string, bool, error) {
+	decoder := newHTMLDecoder(strings.NewReader(markup))
+
+	var out strings.Builder
+	// open are the qualified names of the written elements left open
+	var open []string
+	var ns namespaces
+	pendingStart := false
+	closeStart := func() {
+		if pendingStart {
+			out.WriteString(">")
+			pendingStart = false
+		}
+	}
+	skipped, written, cut := 0, 0, false
+
+	for !cut {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skipped > 0 || clean && droppedElements[t.Name.Local] {
+				skipped++
+				continue
+			}
+			if strip {
+				if blockElements[t.Name.Local] {
+					out.WriteString(" ")
+				}
+				continue
+			}
+			closeStart()
+			ns.push(t.Attr)
+			name := ns.qualified(t.Name)
+			out.WriteString("<" + name)
+			for _, attr := range t.Attr {
+				out.WriteString(" " + ns.qualified(attr.Name) + `="`)
+				xml.EscapeText(&out, []byte(attr.Value))
+				out.WriteString(`"`)
+			}
+			pendingStart = true
+			open = append(open, name)
+		case xml.EndElement:
+			if skipped > 0 {
+				skipped--
+				continue
+			}
+			if strip {
+				if blockElements[t.Name.Local] {
+					out.WriteString(" ")
+				}
+				continue
+			}
+			if pendingStart {
+				out.WriteString("/>")
+				pendingStart = false
+			} else {
+				out.WriteString("</" + ns.qualified(t.Name) + ">")
+			}
+			ns.pop()
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		case xml.CharData:
+			if skipped > 0 {
+				continue
+			}
+			text := string(t)
+			if clean {
+				text = collapseWhitespace(text)
+			}
+			if length := utf8.RuneCountInString(text); maxText > 0 && written+length > maxText {
+				text = truncateText(text, maxText-written)
+				cut = true
+			}
+			written += utf8.RuneCountInString(text)
+			closeStart()
+			if strip {
+				out.WriteString(text)
+			} else {
+				xml.EscapeText(&out, []byte(text))
+			}
+		case xml.Comment, xml.ProcInst, xml.Directive:
+			if !clean && !strip && skipped == 0 {
+				closeStart()
+				out.WriteString(markupOf(t))
+			}
+		}
+	}
+
+	if strip {
+		return collapseSpaces(out.String()), cut, nil
+	}
+	closeStart()
+	if cut {
+		for i := len(open) - 1; i >= 0; i-- {
+			out.WriteString("</" + open[i] + ">")
+		}
+	}
+	return out.String(), cut, nil
+}
+
+// namespaces are the prefixes declared by the open elements, by namespace URI. The decoder resolves the prefixes
+// of the names to their namespace URI, they are written back with the prefix of the markup.
+type namespaces []map[string]string
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// push declares the prefixes of the xmlns attributes of an element, the empty prefix for its default namespace.
+func (ns *namespaces) push(attrs []xml.Attr) {
+	scope := map[string]string{}
+	for _, attr := range attrs {
+		switch {
+		case attr.Name.Space == "xmlns":
+			scope[attr.Value] = attr.Name.Local
+		case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+			scope[attr.Value] = ""
+		}
+	}
+	*ns = append(*ns, scope)
+}
+
+// pop forgets the prefixes of the element being closed.
+func (ns *namespaces) pop() {
+	if len(*ns) > 0 {
+		*ns = (*ns)[:len(*ns)-1]
+	}
+}
+
+func (ns namespaces) qualified(name xml.Name) string {
+	switch name.Space {
+	case "":
+		return name.Local
+	case "xmlns":
+		return "xmlns:" + name.Local
+	case xmlNamespace:
+		return "xml:" + name.Local
+	}
+	for i := len(ns) - 1; i >= 0; i-- {
+		if prefix, ok := ns[i][name.Space]; ok {
+			if prefix == "" {
+				return name.Local
+			}
+			return prefix + ":" + name.Local
+		}
+	}
+	// the decoder keeps the prefixes that are not declared
+	return name.Space + ":" + name.Local
+}
+
+func markupOf(token xml.Token) string {
+	switch t := token.(type) {
+	case xml.Comment:
+		return "<!--" + string(t) + "-->"
+	case xml.ProcInst:
+		return "<?" + t.Target + " " + string(t.Inst) + "?>"
+	case xml.Directive:
+		return "<!" + string(t) + ">"
+	}
+	return ""
+}
+
+// collapseWhitespace replaces the runs of whitespace by a single space.
+func collapseWhitespace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if !space {
+				b.WriteRune(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// collapseSpaces separates the words of s by a single space.
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncateText returns the first max characters of s, cut after the last whole word when there is one.
+func truncateText(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	cut := runes[:max]
+	if !unicode.IsSpace(runes[max]) {
+		for i := len(cut) - 1; i > 0; i-- {
+			if unicode.IsSpace(cut[i]) {
+				cut = cut[:i]
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(cut), unicode.IsSpace)
+}
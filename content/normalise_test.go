@@ -0,0 +1,150 @@
+package content
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBodyXML = `<body><content data-embedded="true" id="c0cc4ca2" type="http://www.ft.com/ontology/content/ImageSet"></content>` +
+	`<p>US stocks   see-sawed in early trading<br/>on Tuesday.</p><!-- note --><p>Volatility &amp; calm.</p></body>`
+
+func TestNewNormaliser(t *testing.T) {
+	_, err := NewNormaliser("tidy", 0)
+	assert.EqualError(t, err, `unknown markup mode "tidy", only keep, normalise and strip are supported`)
+	_, err = NewNormaliser(MarkupKeep, -1)
+	assert.EqualError(t, err, "the maximum body length -1 is negative")
+}
+
+func TestNormaliser_Normalise(t *testing.T) {
+	testCases := []struct {
+		name            string
+		markup          string
+		maxBodyLength   int
+		payload         map[string]interface{}
+		expected        map[string]interface{}
+		transformations []Transformation
+	}{
+		{
+			name:     "unchanged",
+			markup:   MarkupKeep,
+			payload:  map[string]interface{}{"title": "title", "bodyXML": testBodyXML},
+			expected: map[string]interface{}{"title": "title", "bodyXML": testBodyXML},
+		},
+		{
+			name:     "normalised",
+			markup:   MarkupNormalise,
+			payload:  map[string]interface{}{"title": "title", "bodyXML": testBodyXML},
+			expected: map[string]interface{}{"title": "title", "bodyXML": `<body><p>US stocks see-sawed in early trading<br/>on Tuesday.</p><p>Volatility &amp; calm.</p></body>`},
+			transformations: []Transformation{
+				{Field: "bodyXML", Transformation: TransformationNormalised, OriginalLength: 221, Length: 101},
+			},
+		},
+		{
+			name:     "stripped",
+			markup:   MarkupStrip,
+			payload:  map[string]interface{}{"bodyXML": testBodyXML, "body": "<p>Hello <b>world</b></p>"},
+			expected: map[string]interface{}{"bodyXML": "US stocks see-sawed in early trading on Tuesday. Volatility & calm.", "body": "Hello world"},
+			transformations: []Transformation{
+				{Field: "bodyXML", Transformation: TransformationStripped, OriginalLength: 221, Length: 67},
+				{Field: "body", Transformation: TransformationStripped, OriginalLength: 25, Length: 11},
+			},
+		},
+		{
+			name:          "stripped and truncated",
+			markup:        MarkupStrip,
+			maxBodyLength: 20,
+			payload:       map[string]interface{}{"bodyXML": testBodyXML, "bodyText": "short"},
+			expected:      map[string]interface{}{"bodyXML": "US stocks see-sawed", "bodyText": "short"},
+			transformations: []Transformation{
+				{Field: "bodyXML", Transformation: TransformationStripped, OriginalLength: 221, Length: 67},
+				{Field: "bodyXML", Transformation: TransformationTruncated, OriginalLength: 67, Length: 19},
+			},
+		},
+		{
+			name:          "markup truncated",
+			markup:        MarkupKeep,
+			maxBodyLength: 30,
+			payload:       map[string]interface{}{"bodyXML": "<body><p>US stocks see-sawed in early trading</p><p>on Tuesday</p></body>"},
+			expected:      map[string]interface{}{"bodyXML": "<body><p>US stocks see-sawed in early</p></body>"},
+			transformations: []Transformation{
+				{Field: "bodyXML", Transformation: TransformationTruncated, OriginalLength: 73, Length: 48},
+			},
+		},
+		{
+			name:     "namespaced normalised",
+			markup:   MarkupNormalise,
+			payload:  map[string]interface{}{"bodyXML": `<body xml:lang="en" xmlns:ft="http://www.ft.com/ontology/content"><!-- note --><p>US stocks <ft:concept id="apple">Apple</ft:concept></p></body>`},
+			expected: map[string]interface{}{"bodyXML": `<body xml:lang="en" xmlns:ft="http://www.ft.com/ontology/content"><p>US stocks <ft:concept id="apple">Apple</ft:concept></p></body>`},
+			transformations: []Transformation{
+				{Field: "bodyXML", Transformation: TransformationNormalised, OriginalLength: 144, Length: 131},
+			},
+		},
+		{
+			name:          "namespaced truncated",
+			markup:        MarkupKeep,
+			maxBodyLength: 13,
+			payload:       map[string]interface{}{"bodyXML": `<body xml:lang="en" xmlns:ft="http://www.ft.com/ontology/content"><p>US stocks <ft:concept id="apple">Apple Inc</ft:concept></p></body>`},
+			expected:      map[string]interface{}{"bodyXML": `<body xml:lang="en" xmlns:ft="http://www.ft.com/ontology/content"><p>US stocks <ft:concept id="apple">App</ft:concept></p></body>`},
+			transformations: []Transformation{
+				{Field: "bodyXML", Transformation: TransformationTruncated, OriginalLength: 135, Length: 129},
+			},
+		},
+		{
+			name:          "text truncated",
+			markup:        MarkupKeep,
+			maxBodyLength: 10,
+			payload:       map[string]interface{}{"bodyText": "US stocks see-sawed"},
+			expected:      map[string]interface{}{"bodyText": "US stocks"},
+			transformations: []Transformation{
+				{Field: "bodyText", Transformation: TransformationTruncated, OriginalLength: 19, Length: 9},
+			},
+		},
+		{
+			name:          "markup within the limit",
+			markup:        MarkupKeep,
+			maxBodyLength: 12,
+			payload:       map[string]interface{}{"body": "<p>US stocks</p>"},
+			expected:      map[string]interface{}{"body": "<p>US stocks</p>"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := NewNormaliser(tc.markup, tc.maxBodyLength)
+			require.NoError(t, err)
+			payload, err := json.Marshal(tc.payload)
+			require.NoError(t, err)
+
+			normalised, transformations, err := n.Normalise(payload)
+			require.NoError(t, err)
+			var actual map[string]interface{}
+			require.NoError(t, json.Unmarshal(normalised, &actual))
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.transformations, transformations)
+			if len(tc.transformations) == 0 {
+				assert.Equal(t, payload, normalised, "the payload is returned as it is")
+			}
+		})
+	}
+}
+
+func TestNormaliser_NormaliseMalformed(t *testing.T) {
+	n, err := NewNormaliser(MarkupStrip, 0)
+	require.NoError(t, err)
+	normalised, transformations, err := n.Normalise([]byte(`{"body":"<p>unclosed <a href=\"x>link</p>"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"body":"unclosed link"}`, string(normalised))
+	assert.Len(t, transformations, 1)
+
+	_, _, err = n.Normalise([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "short", truncateText("short", 10))
+	assert.Equal(t, "two", truncateText("two words", 5))
+	assert.Equal(t, "two", truncateText("two words", 4))
+	assert.Equal(t, "unbreakab", truncateText("unbreakable", 9))
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/content"
+	"github.com/Financial-Times/public-suggestions-api/fakes"
+	"github.com/Financial-Times/public-suggestions-api/feedback"
+	"github.com/Financial-Times/public-suggestions-api/history"
+	"github.com/Financial-Times/public-suggestions-api/monitoring"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/Financial-Times/public-suggestions-api/web"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// apiSpec is the part of the Swagger 2.0 spec in _ft/api.yml the contract tests check the responses against.
+type apiSpec struct {
+	Definitions map[string]*apiSchema               `yaml:"definitions"`
+	Paths       map[string]map[string]*apiOperation `yaml:"paths"`
+}
+
+type apiOperation struct {
+	Responses map[int]*apiResponse `yaml:"responses"`
+}
+
+type apiResponse struct {
+	Schema *apiSchema `yaml:"schema"`
+}
+
+type apiSchema struct {
+	Ref                  string                `yaml:"$ref"`
+	Type                 string                `yaml:"type"`
+	Properties           map[string]*apiSchema `yaml:"properties"`
+	Required             []string              `yaml:"required"`
+	Items                *apiSchema            `yaml:"items"`
+	Enum                 []string              `yaml:"enum"`
+	AdditionalProperties *additionalProperties `yaml:"additionalProperties"`
+}
+
+// additionalProperties is either a boolean or the schema of the additional properties.
+type additionalProperties struct {
+	Allowed bool
+	Schema  *apiSchema
+}
+
+func (a *additionalProperties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&a.Allowed)
+	}
+	a.Allowed = true
+	return node.Decode(&a.Schema)
+}
+
+func loadAPISpec(t *testing.T) *apiSpec {
+	data, err := ioutil.ReadFile("_ft/api.yml")
+	require.NoError(t, err)
+	var spec apiSpec
+	require.NoError(t, yaml.Unmarshal(data, &spec))
+	return &spec
+}
+
+// validate returns the violations of the schema by the decoded JSON value.
+func (spec *apiSpec) validate(s *apiSchema, value interface{}, at string) []string {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/definitions/")
+		def, ok := spec.Definitions[name]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown definition %s", at, s.Ref)}
+		}
+		return spec.validate(def, value, at)
+	}
+
+	var violations []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if s.Type != "" && s.Type != "object" {
+			return []string{fmt.Sprintf("%s: object instead of %s", at, s.Type)}
+		}
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: required property %s is missing", at, name))
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				violations = append(violations, spec.validate(property, v[name], at+"."+name)...)
+				continue
+			}
+			switch {
+			case s.AdditionalProperties == nil || s.AdditionalProperties.Allowed && s.AdditionalProperties.Schema == nil:
+			case !s.AdditionalProperties.Allowed:
+				violations = append(violations, fmt.Sprintf("%s: additional property %s is not allowed", at, name))
+			default:
+				violations = append(violations, spec.validate(s.AdditionalProperties.Schema, v[name], at+"."+name)...)
+			}
+		}
+	case []interface{}:
+		if s.Type != "" && s.Type != "array" {
+			return []string{fmt.Sprintf("%s: array instead of %s", at, s.Type)}
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				violations = append(violations, spec.validate(s.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case string:
+		if s.Type != "" && s.Type != "string" {
+			return []string{fmt.Sprintf("%s: string instead of %s", at, s.Type)}
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, v) {
+			violations = append(violations, fmt.Sprintf("%s: %q is not one of %v", at, v, s.Enum))
+		}
+	case float64:
+		if s.Type == "integer" && v != float64(int64(v)) {
+			return []string{fmt.Sprintf("%s: %v is not an integer", at, v)}
+		}
+		if s.Type != "" && s.Type != "number" && s.Type != "integer" {
+			return []string{fmt.Sprintf("%s: number instead of %s", at, s.Type)}
+		}
+	case bool:
+		if s.Type != "" && s.Type != "boolean" {
+			return []string{fmt.Sprintf("%s: boolean instead of %s", at, s.Type)}
+		}
+	case nil:
+		return []string{fmt.Sprintf("%s: null", at)}
+	}
+	return violations
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	contractContentUUID  = "3b4bd8f2-1e3c-4d8a-9f2b-5c6d7e8f9a0b"
+	contractMissingUUID  = "f1b2c3d4-0000-4000-8000-000000000001"
+	contractInvalidUUID  = "9d5e441e-0b02-11e8-8eb7-42f857ea9f0"
+	contractNotJSONUUID  = "f1b2c3d4-0000-4000-8000-000000000002"
+	contractUnreadUUID   = "f1b2c3d4-0000-4000-8000-000000000003"
+	contractDownFixtures = `
+fixtures:
+  /content/suggest/ontotext:
+    post:
+      body:
+        suggestions:
+          - id: http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a55
+            predicate: http://www.ft.com/ontology/annotation/about
+  /content/suggest/authors:
+    post:
+      status: 503
+  /internalconcordances:
+    get:
+      status: 503
+  /blacklist:
+    get:
+      status: 503
+`
+)
+
+// newContractServer serves the real router of the service, every store and reader set, with the downstream services
+// faked by the fixtures.
+func newContractServer(t *testing.T, fixtures *fakes.Fixtures) *httptest.Server {
+	downstream := httptest.NewServer(fixtures)
+	t.Cleanup(downstream.Close)
+	c := downstream.Client()
+
+	log := logger.NewUPPLogger("test-service", "panic")
+	authorsSuggester := service.NewAuthorsSuggester(downstream.URL, "/content/suggest/authors", c)
+	ontotextSuggester := service.NewOntotextSuggester(downstream.URL, "/content/suggest/ontotext", c)
+	concordance := service.NewConcordance(downstream.URL, "/internalconcordances", c)
+	broaderProvider := service.NewBroaderConceptsProvider(downstream.URL, "/things", c)
+	blacklister := service.NewConceptBlacklister(downstream.URL, "/blacklist", c)
+	contentAPI := service.NewContentAPI(downstream.URL, "/content", c)
+	suggester := service.NewAggregateSuggester(log, concordance, broaderProvider, blacklister, authorsSuggester, ontotextSuggester)
+	healthService := web.NewHealthService("mock", "mock", "", authorsSuggester.Check(), ontotextSuggester.Check(),
+		concordance.Check(), broaderProvider.Check(), blacklister.Check(), contentAPI.Check())
+
+	feedbackStore, err := feedback.OpenBoltStore(filepath.Join(t.TempDir(), "feedback.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { feedbackStore.Close() })
+	historyStore, err := history.OpenBoltStore(filepath.Join(t.TempDir(), "history.db"), 5)
+	require.NoError(t, err)
+	t.Cleanup(func() { historyStore.Close() })
+
+	requestHandler := web.NewRequestHandler(suggester, log)
+	requestHandler.History = historyStore
+	requestHandler.ContentReader = contentAPI
+	contentValidator, err := content.NewValidator(content.Rules{TextFields: content.DefaultTextFields})
+	require.NoError(t, err)
+	requestHandler.ContentValidator = contentValidator
+	normaliser, err := content.NewNormaliser(content.MarkupNormalise, 0)
+	require.NoError(t, err)
+	requestHandler.Normaliser = normaliser
+	requestHandler.MaxPayloadBytes = 1 << 10
+
+	apiHandler, err := web.NewAPIHandler(apiDocument, "1.2.3")
+	require.NoError(t, err)
+
+	feedbackHandler := web.NewFeedbackHandler(feedbackStore, log)
+	feedbackHandler.History = historyStore
+
+	server := httptest.NewServer(newServeMux(requestHandler, feedbackHandler,
+		web.NewHistoryHandler(historyStore, log), healthService, monitoring.New().Handler(), apiHandler, log))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestContract(t *testing.T) {
+	spec := loadAPISpec(t)
+
+	up, err := fakes.LoadFile("_ft/fakes.yml")
+	require.NoError(t, err)
+	up.Fixtures["/content/"+contractNotJSONUUID] = map[string]*fakes.Endpoint{
+		"get": {Response: fakes.Response{Status: http.StatusOK, Body: `["not","an","object"]`}},
+	}
+	up.Fixtures["/content/"+contractUnreadUUID] = map[string]*fakes.Endpoint{
+		"get": {Response: fakes.Response{Status: http.StatusInternalServerError}},
+	}
+	down, err := fakes.Parse([]byte(contractDownFixtures))
+	require.NoError(t, err)
+	servers := map[string]*httptest.Server{
+		"up":   newContractServer(t, up),
+		"down": newContractServer(t, down),
+	}
+
+	payload := `{"id":"http://www.ft.com/thing/` + contractContentUUID + `","bodyXML":"<body>Apple</body>",` +
+		`"existingAnnotations":[{"id":"http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a55","predicate":"http://www.ft.com/ontology/annotation/mentions"},` +
+		`{"id":"http://www.ft.com/thing/00000000-0000-0000-0000-000000000000"}]}`
+	feedbackBody := `{"contentType":"Article","decisions":[{"id":"http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a55",` +
+		`"type":"http://www.ft.com/ontology/organisation/Organisation","source":"Ontotext Suggestion API","decision":"accepted"}]}`
+
+	// in order, the history is read after the content was suggested
+	testCases := []struct {
+		server string
+		method string
+		path   string
+		url    string
+		body   string
+		status int
+		// contentType is only set for the payloads that are not JSON
+		contentType string
+	}{
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest?timings=true", payload, http.StatusOK, ""},
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest", "Apple unveils a new phone.", http.StatusOK, "text/plain"},
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest", "<html><body><p>Apple</p></body></html>", http.StatusOK, "text/html"},
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest", "<content><title>Apple</title></content>", http.StatusOK, "application/xml"},
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest?format=grouped", payload, http.StatusOK, ""},
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest", "wrong_json", http.StatusBadRequest, ""},
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest?format=flat", payload, http.StatusBadRequest, ""},
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest", "<content><title>Apple</content>", http.StatusBadRequest, "application/xml"},
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest", "title=Apple", http.StatusUnsupportedMediaType, "application/x-www-form-urlencoded"},
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest", `{"foo":1,"title":2}`, http.StatusBadRequest, ""},
+		{"up", http.MethodPost, "/content/suggest", "/content/suggest", `{"bodyXML":"` + strings.Repeat("text ", 1<<10) + `"}`, http.StatusRequestEntityTooLarge, ""},
+		{"down", http.MethodPost, "/content/suggest", "/content/suggest", payload, http.StatusServiceUnavailable, ""},
+		{"up", http.MethodGet, "/content/{uuid}/suggest", "/content/" + contractContentUUID + "/suggest", "", http.StatusOK, ""},
+		{"up", http.MethodGet, "/content/{uuid}/suggest", "/content/" + contractContentUUID + "/suggest?format=grouped", "", http.StatusOK, ""},
+		{"up", http.MethodGet, "/content/{uuid}/suggest", "/content/" + contractInvalidUUID + "/suggest", "", http.StatusBadRequest, ""},
+		{"up", http.MethodGet, "/content/{uuid}/suggest", "/content/" + contractMissingUUID + "/suggest", "", http.StatusNotFound, ""},
+		{"up", http.MethodGet, "/content/{uuid}/suggest", "/content/" + contractNotJSONUUID + "/suggest", "", http.StatusBadGateway, ""},
+		{"up", http.MethodGet, "/content/{uuid}/suggest", "/content/" + contractUnreadUUID + "/suggest", "", http.StatusServiceUnavailable, ""},
+		{"up", http.MethodPost, "/content/{uuid}/suggestions/feedback", "/content/" + contractContentUUID + "/suggestions/feedback", feedbackBody, http.StatusOK, ""},
+		{"up", http.MethodPost, "/content/{uuid}/suggestions/feedback", "/content/" + contractInvalidUUID + "/suggestions/feedback", feedbackBody, http.StatusBadRequest, ""},
+		{"up", http.MethodGet, "/suggestions/feedback/rates", "/suggestions/feedback/rates", "", http.StatusOK, ""},
+		{"up", http.MethodGet, "/suggestions/feedback/rates", "/suggestions/feedback/rates?window=never", "", http.StatusBadRequest, ""},
+		{"up", http.MethodGet, "/content/{uuid}/suggestions/history", "/content/" + contractContentUUID + "/suggestions/history", "", http.StatusOK, ""},
+		{"up", http.MethodGet, "/content/{uuid}/suggestions/history", "/content/" + contractInvalidUUID + "/suggestions/history", "", http.StatusBadRequest, ""},
+		{"up", http.MethodGet, "/content/{uuid}/suggestions/history", "/content/" + contractMissingUUID + "/suggestions/history", "", http.StatusNotFound, ""},
+		{"up", http.MethodGet, "/__health", "/__health", "", http.StatusOK, ""},
+		{"down", http.MethodGet, "/__health", "/__health", "", http.StatusOK, ""},
+		{"up", http.MethodGet, "/__build-info", "/__build-info", "", http.StatusOK, ""},
+		{"up", http.MethodGet, "/__api", "/__api", "", http.StatusOK, ""},
+		{"up", http.MethodGet, "/metrics", "/metrics", "", http.StatusOK, ""},
+		{"up", http.MethodGet, "/__gtg", "/__gtg", "", http.StatusOK, ""},
+		{"down", http.MethodGet, "/__gtg", "/__gtg", "", http.StatusOK, ""},
+	}
+
+	covered := map[string]bool{}
+	for _, tc := range testCases {
+		name := fmt.Sprintf("%s %s %d (%s %s)", tc.method, tc.path, tc.status, tc.server, tc.contentType)
+		operation, ok := spec.Paths[tc.path][strings.ToLower(tc.method)]
+		require.True(t, ok, "%s is not in the spec", name)
+		documented, ok := operation.Responses[tc.status]
+		require.True(t, ok, "%s is not documented", name)
+		covered[fmt.Sprintf("%s %s %d", tc.method, tc.path, tc.status)] = true
+
+		req, err := http.NewRequest(tc.method, servers[tc.server].URL+tc.url, strings.NewReader(tc.body))
+		require.NoError(t, err)
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+
+		if !assert.Equal(t, tc.status, resp.StatusCode, "%s: %s", name, body) || documented.Schema == nil {
+			continue
+		}
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"), name)
+		var value interface{}
+		if assert.NoError(t, json.Unmarshal(body, &value), name) {
+			assert.Empty(t, spec.validate(documented.Schema, value, "body"), "%s: %s", name, body)
+		}
+	}
+
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			for status := range operation.Responses {
+				key := fmt.Sprintf("%s %s %d", strings.ToUpper(method), path, status)
+				assert.True(t, covered[key], "%s is documented but not tested", key)
+			}
+		}
+	}
+}
+
+func TestRoutesInSpec(t *testing.T) {
+	spec := loadAPISpec(t)
+	log := logger.NewUPPLogger("test-service", "panic")
+	handler := web.NewRequestHandler(nil, log)
+	handler.ContentReader = service.ContentDir("")
+
+	router := newServicesRouter(handler, web.NewFeedbackHandler(nil, log), web.NewHistoryHandler(nil, log))
+	routes := 0
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		require.NoError(t, err)
+		methods, err := route.GetMethods()
+		require.NoError(t, err)
+		for _, method := range methods {
+			_, ok := spec.Paths[path][strings.ToLower(method)]
+			assert.True(t, ok, "%s %s is routed but not in the spec", method, path)
+			routes++
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, routes)
+
+	for path := range adminHandlers(web.NewHealthService("mock", "mock", ""), nil, nil) {
+		_, ok := spec.Paths[path]["get"]
+		assert.True(t, ok, "GET %s is routed but not in the spec", path)
+	}
+}
+
+func TestAPISpec_Validate(t *testing.T) {
+	spec := loadAPISpec(t)
+	suggestion := &apiSchema{Ref: "#/definitions/suggestion"}
+
+	valid := map[string]interface{}{"id": "id", "predicate": "p", "apiUrl": "url", "prefLabel": "label", "type": "type", "status": "new"}
+	assert.Empty(t, spec.validate(suggestion, valid, "suggestion"))
+
+	invalid := map[string]interface{}{"id": "id", "predicate": "p", "apiUrl": "url", "type": 1.0, "status": "old", "score": 0.5}
+	assert.Equal(t, []string{
+		"suggestion: required property prefLabel is missing",
+		"suggestion: additional property score is not allowed",
+		`suggestion.status: "old" is not one of [new present conflicting]`,
+		"suggestion.type: number instead of string",
+	}, spec.validate(suggestion, invalid, "suggestion"))
+}
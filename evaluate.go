@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/evaluate"
+	cli "github.com/jawher/mow.cli"
+)
+
+const evaluateDescription = "Score the suggestions against a corpus of editor curated annotations. The downstream services are configured by the application options"
+
+func evaluateCommand(cmd *cli.Cmd, log *logger.UPPLogger, newSuggester func() evaluate.Suggester) {
+	cmd.Spec = "[--corpus] [--format] [--concurrency]"
+
+	corpus := cmd.String(cli.StringOpt{
+		Name:  "corpus",
+		Value: "-",
+		Desc:  `NDJSON file with one {"content": {...}, "annotations": [...]} item per line, - for stdin`,
+	})
+	format := cmd.String(cli.StringOpt{
+		Name:  "format",
+		Value: "text",
+		Desc:  "Result format: text or json",
+	})
+	concurrency := cmd.Int(cli.IntOpt{
+		Name:  "concurrency",
+		Value: 4,
+		Desc:  "Number of content items evaluated in parallel",
+	})
+
+	cmd.Action = func() {
+		// keep stdout for the result
+		log.Out = os.Stderr
+		if err := runEvaluate(newSuggester(), *corpus, *format, *concurrency, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "evaluation failed: %v\n", err)
+			cli.Exit(1)
+		}
+	}
+}
+
+func runEvaluate(suggester evaluate.Suggester, corpus, format string, concurrency int, out io.Writer) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown result format %q", format)
+	}
+
+	in := io.Reader(os.Stdin)
+	if corpus != "-" {
+		f, err := os.Open(corpus)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	result, err := evaluate.Run(context.Background(), in, suggester, concurrency)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		return result.WriteJSON(out)
+	}
+	return result.WriteText(out)
+}
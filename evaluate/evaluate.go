@@ -0,0 +1,293 @@
+// Package evaluate scores the aggregated suggestions against editor curated annotations.
+package evaluate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	fp "path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Financial-Times/public-suggestions-api/service"
+)
+
+const (
+	Origin       = "public-suggestions-api-evaluate"
+	maxLineBytes = 16 * 1024 * 1024
+	unknown      = "unknown"
+)
+
+// Suggester is implemented by service.AggregateSuggester.
+type Suggester interface {
+	GetSuggestions(ctx context.Context, payload []byte, tid, origin string) (service.SuggestionsResponse, error)
+}
+
+// Item is one line of the corpus: a content payload and the annotations the editors curated for it.
+type Item struct {
+	Content     json.RawMessage      `json:"content"`
+	Annotations []service.Suggestion `json:"annotations"`
+}
+
+// Score counts the matches of the suggestions with the annotations.
+type Score struct {
+	TruePositives  int     `json:"truePositives"`
+	FalsePositives int     `json:"falsePositives"`
+	FalseNegatives int     `json:"falseNegatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+func (s *Score) compute() {
+	s.Precision = ratio(s.TruePositives, s.TruePositives+s.FalsePositives)
+	s.Recall = ratio(s.TruePositives, s.TruePositives+s.FalseNegatives)
+	if s.Precision+s.Recall > 0 {
+		s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+	}
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// ItemError records a content item the suggestions could not be computed for.
+type ItemError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// Result is the outcome of an evaluation.
+// The recall of a Suggester is measured against all the annotations of the items, not only the ones it could suggest.
+type Result struct {
+	Items         int               `json:"items"`
+	Errors        []ItemError       `json:"errors"`
+	Overall       Score             `json:"overall"`
+	BySuggester   map[string]*Score `json:"bySuggester"`
+	ByConceptType map[string]*Score `json:"byConceptType"`
+	ByPredicate   map[string]*Score `json:"byPredicate"`
+}
+
+// Run computes the suggestions of every item of the NDJSON corpus, with at most concurrency items in flight,
+// and scores them against the annotations of the item.
+func Run(ctx context.Context, corpus io.Reader, suggester Suggester, concurrency int) (*Result, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	items, err := readCorpus(corpus)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Items:         len(items),
+		Errors:        []ItemError{},
+		BySuggester:   map[string]*Score{},
+		ByConceptType: map[string]*Score{},
+		ByPredicate:   map[string]*Score{},
+	}
+	mutex := sync.Mutex{}
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for _, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(item corpusItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			report := &service.Report{}
+			tid := fmt.Sprintf("tid_evaluate_%d", item.line)
+			resp, err := suggester.GetSuggestions(service.ContextWithReport(ctx, report), item.Content, tid, Origin)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				result.Errors = append(result.Errors, ItemError{Line: item.line, Error: err.Error()})
+				return
+			}
+			result.add(item.Annotations, resp.Suggestions, report.Sources())
+		}(item)
+	}
+	wg.Wait()
+
+	sort.Slice(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Line < result.Errors[j].Line
+	})
+	result.Overall.compute()
+	for _, scores := range []map[string]*Score{result.BySuggester, result.ByConceptType, result.ByPredicate} {
+		for _, s := range scores {
+			s.compute()
+		}
+	}
+	return result, nil
+}
+
+type corpusItem struct {
+	Item
+	line int
+}
+
+func readCorpus(input io.Reader) ([]corpusItem, error) {
+	var items []corpusItem
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		body := bytes.TrimSpace(scanner.Bytes())
+		if len(body) == 0 {
+			continue
+		}
+		item := corpusItem{line: line}
+		if err := json.Unmarshal(body, &item.Item); err != nil {
+			return nil, fmt.Errorf("line %d is not a corpus item: %w", line, err)
+		}
+		if len(item.Content) == 0 {
+			return nil, fmt.Errorf("line %d has no content", line)
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// add scores the suggestions of one item. A suggestion matches an annotation with the same concept UUID and predicate,
+// the matches are classified by the concept type of the annotation.
+func (r *Result) add(annotations, suggestions []service.Suggestion, sources []service.SourceReport) {
+	expected := map[string]service.Suggestion{}
+	for _, a := range annotations {
+		expected[key(a)] = a
+	}
+
+	matched := map[string]bool{}
+	for _, s := range dedupByKey(suggestions) {
+		if a, ok := expected[key(s)]; ok {
+			matched[key(s)] = true
+			r.Overall.TruePositives++
+			score(r.ByConceptType, conceptType(a, s)).TruePositives++
+			score(r.ByPredicate, predicate(a)).TruePositives++
+			continue
+		}
+		r.Overall.FalsePositives++
+		score(r.ByConceptType, conceptType(s, s)).FalsePositives++
+		score(r.ByPredicate, predicate(s)).FalsePositives++
+	}
+	for k, a := range expected {
+		if matched[k] {
+			continue
+		}
+		r.Overall.FalseNegatives++
+		score(r.ByConceptType, conceptType(a, a)).FalseNegatives++
+		score(r.ByPredicate, predicate(a)).FalseNegatives++
+	}
+
+	for _, source := range sources {
+		if source.Error != "" {
+			continue
+		}
+		s := score(r.BySuggester, source.Source)
+		found := 0
+		for _, suggestion := range dedupByKey(source.Returned) {
+			if _, ok := expected[key(suggestion)]; ok {
+				found++
+				s.TruePositives++
+			} else {
+				s.FalsePositives++
+			}
+		}
+		s.FalseNegatives += len(expected) - found
+	}
+}
+
+func score(scores map[string]*Score, name string) *Score {
+	s, ok := scores[name]
+	if !ok {
+		s = &Score{}
+		scores[name] = s
+	}
+	return s
+}
+
+func key(s service.Suggestion) string {
+	return fp.Base(s.ID) + " " + s.Predicate
+}
+
+func dedupByKey(suggestions []service.Suggestion) []service.Suggestion {
+	seen := map[string]bool{}
+	result := []service.Suggestion{}
+	for _, s := range suggestions {
+		if seen[key(s)] {
+			continue
+		}
+		seen[key(s)] = true
+		result = append(result, s)
+	}
+	return result
+}
+
+// conceptType prefers the type of the annotation, the corpus may omit it.
+func conceptType(annotation, suggestion service.Suggestion) string {
+	if annotation.Type != "" {
+		return service.ConceptTypeName(annotation)
+	}
+	return service.ConceptTypeName(suggestion)
+}
+
+func predicate(s service.Suggestion) string {
+	if s.Predicate == "" {
+		return unknown
+	}
+	return fp.Base(s.Predicate)
+}
+
+// WriteText writes the scores as tables.
+func (r *Result) WriteText(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "Evaluated %d content items, %d failed\n\n", r.Items, len(r.Errors))
+	fmt.Fprintf(b, "%-30s %8s %8s %8s %6s %6s %6s\n", "", "TP", "FP", "FN", "P", "R", "F1")
+	writeScore(b, "overall", &r.Overall)
+	writeScores(b, "By suggester", r.BySuggester)
+	writeScores(b, "By concept type", r.ByConceptType)
+	writeScores(b, "By predicate", r.ByPredicate)
+	for _, e := range r.Errors {
+		fmt.Fprintf(b, "\nline %d: %s", e.Line, e.Error)
+	}
+	if len(r.Errors) > 0 {
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeScores(b *strings.Builder, title string, scores map[string]*Score) {
+	if len(scores) == 0 {
+		return
+	}
+	names := make([]string, 0, len(scores))
+	for name := range scores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(b, "\n%s\n", title)
+	for _, name := range names {
+		writeScore(b, "  "+name, scores[name])
+	}
+}
+
+func writeScore(b *strings.Builder, name string, s *Score) {
+	fmt.Fprintf(b, "%-30s %8d %8d %8d %6.3f %6.3f %6.3f\n", name, s.TruePositives, s.FalsePositives, s.FalseNegatives, s.Precision, s.Recall, s.F1)
+}
+
+// WriteJSON writes the full result as JSON.
+func (r *Result) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,144 @@
+package evaluate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mentions  = "http://www.ft.com/ontology/annotation/mentions"
+	hasAuthor = "http://www.ft.com/ontology/annotation/hasAuthor"
+	person    = "http://www.ft.com/ontology/person/Person"
+	location  = "http://www.ft.com/ontology/Location"
+)
+
+var concepts = map[string]service.Concept{
+	"london": {ID: "http://www.ft.com/thing/london", PrefLabel: "London", Type: location},
+	"paris":  {ID: "http://www.ft.com/thing/paris", PrefLabel: "Paris", Type: location},
+	"alice":  {ID: "http://www.ft.com/thing/alice", PrefLabel: "Alice", Type: person},
+	"bob":    {ID: "http://www.ft.com/thing/bob", PrefLabel: "Bob", Type: person},
+}
+
+// newDownstreams serves the suggesters, the concordances, the broader concepts and the blacklist of the test.
+func newDownstreams(t *testing.T) *httptest.Server {
+	suggestions := map[string]string{
+		"/ontotext": `{"suggestions":[
+			{"id":"http://www.ft.com/thing/london","predicate":"` + mentions + `"},
+			{"id":"http://www.ft.com/thing/paris","predicate":"` + mentions + `"}]}`,
+		"/authors": `{"suggestions":[
+			{"id":"http://www.ft.com/thing/alice","predicate":"` + hasAuthor + `"}]}`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ontotext", "/authors":
+			w.Write([]byte(suggestions[r.URL.Path]))
+		case "/concordances":
+			resp := service.ConcordanceResponse{Concepts: map[string]service.Concept{}}
+			for _, id := range r.URL.Query()["ids"] {
+				resp.Concepts[id] = concepts[id]
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+		case "/things":
+			w.Write([]byte(`{"things":{}}`))
+		case "/blacklist":
+			w.Write([]byte(`{"uuids":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRun(t *testing.T) {
+	server := newDownstreams(t)
+	defer server.Close()
+	client := server.Client()
+	suggester := service.NewAggregateSuggester(logger.NewUPPLogger("test-service", "panic"),
+		service.NewConcordance(server.URL, "/concordances", client),
+		service.NewBroaderConceptsProvider(server.URL, "/things", client),
+		service.NewConceptBlacklister(server.URL, "/blacklist", client),
+		service.NewAuthorsSuggester(server.URL, "/authors", client),
+		service.NewOntotextSuggester(server.URL, "/ontotext", client))
+
+	corpus := strings.NewReader(`{"content":{"id":"a"},"annotations":[` +
+		`{"id":"http://api.ft.com/things/london","predicate":"` + mentions + `","type":"` + location + `"},` +
+		`{"id":"http://www.ft.com/thing/bob","predicate":"` + mentions + `","type":"` + person + `"},` +
+		`{"id":"http://www.ft.com/thing/alice","predicate":"` + hasAuthor + `","type":"` + person + `"}]}` + "\n")
+	result, err := Run(context.Background(), corpus, suggester, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Items)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, Score{TruePositives: 2, FalsePositives: 1, FalseNegatives: 1, Precision: 2.0 / 3, Recall: 2.0 / 3, F1: 2.0 / 3}, result.Overall)
+
+	require.Contains(t, result.BySuggester, "Ontotext Suggestion API")
+	require.Contains(t, result.BySuggester, "Authors Suggestion API")
+	assert.Equal(t, Score{TruePositives: 1, FalsePositives: 1, FalseNegatives: 2, Precision: 0.5, Recall: 1.0 / 3, F1: 0.4}, *result.BySuggester["Ontotext Suggestion API"])
+	assert.Equal(t, Score{TruePositives: 1, FalseNegatives: 2, Precision: 1, Recall: 1.0 / 3, F1: 0.5}, *result.BySuggester["Authors Suggestion API"])
+
+	assert.Equal(t, 1, result.ByConceptType["Location"].TruePositives)
+	assert.Equal(t, 1, result.ByConceptType["Location"].FalsePositives)
+	assert.Equal(t, 1, result.ByConceptType["Person"].TruePositives)
+	assert.Equal(t, 1, result.ByConceptType["Person"].FalseNegatives)
+
+	assert.Equal(t, Score{TruePositives: 1, Precision: 1, Recall: 1, F1: 1}, *result.ByPredicate["hasAuthor"])
+	assert.Equal(t, 1, result.ByPredicate["mentions"].TruePositives)
+	assert.Equal(t, 1, result.ByPredicate["mentions"].FalsePositives)
+	assert.Equal(t, 1, result.ByPredicate["mentions"].FalseNegatives)
+}
+
+type failingSuggester struct{}
+
+func (failingSuggester) GetSuggestions(context.Context, []byte, string, string) (service.SuggestionsResponse, error) {
+	return service.SuggestionsResponse{}, errors.New("downstream unavailable")
+}
+
+func TestRun_Errors(t *testing.T) {
+	result, err := Run(context.Background(), strings.NewReader(`{"content":{"id":"a"},"annotations":[]}`), failingSuggester{}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []ItemError{{Line: 1, Error: "downstream unavailable"}}, result.Errors)
+	assert.Equal(t, Score{}, result.Overall)
+}
+
+func TestRun_InvalidCorpus(t *testing.T) {
+	_, err := Run(context.Background(), strings.NewReader("{\"content\":{}}\nnot json\n"), failingSuggester{}, 1)
+	assert.EqualError(t, err, "line 2 is not a corpus item: invalid character 'o' in literal null (expecting 'u')")
+
+	_, err = Run(context.Background(), strings.NewReader(`{"annotations":[]}`), failingSuggester{}, 1)
+	assert.EqualError(t, err, "line 1 has no content")
+}
+
+func TestResult_Write(t *testing.T) {
+	result := &Result{
+		Items:         2,
+		Errors:        []ItemError{{Line: 2, Error: "downstream unavailable"}},
+		Overall:       Score{TruePositives: 1, FalsePositives: 1, Precision: 0.5, Recall: 1, F1: 2.0 / 3},
+		BySuggester:   map[string]*Score{"Ontotext Suggestion API": {TruePositives: 1, FalsePositives: 1, Precision: 0.5, Recall: 1, F1: 2.0 / 3}},
+		ByConceptType: map[string]*Score{},
+		ByPredicate:   map[string]*Score{},
+	}
+
+	text := &bytes.Buffer{}
+	require.NoError(t, result.WriteText(text))
+	assert.Contains(t, text.String(), "Evaluated 2 content items, 1 failed")
+	assert.Contains(t, text.String(), "overall                               1        1        0  0.500  1.000  0.667")
+	assert.Contains(t, text.String(), "By suggester\n  Ontotext Suggestion API")
+	assert.NotContains(t, text.String(), "By concept type")
+	assert.Contains(t, text.String(), "line 2: downstream unavailable")
+
+	out := &bytes.Buffer{}
+	require.NoError(t, result.WriteJSON(out))
+	var decoded Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, *result, decoded)
+}
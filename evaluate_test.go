@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSuggester service.SuggestionsResponse
+
+func (s staticSuggester) GetSuggestions(context.Context, []byte, string, string) (service.SuggestionsResponse, error) {
+	return service.SuggestionsResponse(s), nil
+}
+
+func TestRunEvaluate(t *testing.T) {
+	corpus := filepath.Join(t.TempDir(), "corpus.ndjson")
+	require.NoError(t, ioutil.WriteFile(corpus, []byte(`{"content":{"id":"content-1"},"annotations":[{"id":"http://www.ft.com/thing/1","predicate":"http://www.ft.com/ontology/annotation/mentions"}]}`+"\n"), 0644))
+	suggester := staticSuggester{Suggestions: []service.Suggestion{
+		{Concept: service.Concept{ID: "http://www.ft.com/thing/1", Type: "http://www.ft.com/ontology/Location"}, Predicate: "http://www.ft.com/ontology/annotation/mentions"},
+	}}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, runEvaluate(suggester, corpus, "json", 1, out))
+	assert.Contains(t, out.String(), `"truePositives": 1`)
+	assert.Contains(t, out.String(), `"f1": 1`)
+
+	assert.EqualError(t, runEvaluate(suggester, corpus, "csv", 1, ioutil.Discard), `unknown result format "csv"`)
+}
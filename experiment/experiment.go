@@ -0,0 +1,74 @@
+// Package experiment loads the configuration of the A/B experiment run by the AggregateSuggester.
+package experiment
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the experiment configuration file, e.g.
+//
+//	name: ontotext-only
+//	variants:
+//	  - name: control
+//	    weight: 90
+//	  - name: without-authors
+//	    weight: 10
+//	    suggesters: [ontotext-suggestion-api]
+//	    skipFilters: [broader]
+type Config struct {
+	Name     string          `yaml:"name"`
+	Variants []VariantConfig `yaml:"variants"`
+}
+
+// VariantConfig configures one variant. Without suggesters, the variant uses the default ones.
+type VariantConfig struct {
+	Name        string   `yaml:"name"`
+	Weight      int      `yaml:"weight"`
+	Suggesters  []string `yaml:"suggesters"`
+	SkipFilters []string `yaml:"skipFilters"`
+}
+
+// LoadFile reads an experiment configuration file in YAML (or JSON) format.
+func LoadFile(path string) (Config, error) {
+	var c Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err = yaml.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("parsing experiment configuration %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Build creates the experiment, the variant suggesters are looked up by name in suggesters.
+func (c Config) Build(suggesters map[string]service.Suggester) (*service.Experiment, error) {
+	variants := make([]service.Variant, 0, len(c.Variants))
+	for _, vc := range c.Variants {
+		v := service.Variant{Name: vc.Name, Weight: vc.Weight, SkipFilters: vc.SkipFilters}
+		for _, name := range vc.Suggesters {
+			s, ok := suggesters[name]
+			if !ok {
+				return nil, fmt.Errorf("variant %s uses unknown suggester %q, known suggesters are %s", vc.Name, name, known(suggesters))
+			}
+			v.Suggesters = append(v.Suggesters, s)
+		}
+		variants = append(variants, v)
+	}
+	return service.NewExperiment(c.Name, variants...)
+}
+
+func known(suggesters map[string]service.Suggester) string {
+	names := make([]string, 0, len(suggesters))
+	for name := range suggesters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,57 @@
+package experiment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "experiment.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+name: ontotext-only
+variants:
+  - name: control
+    weight: 90
+  - name: without-authors
+    weight: 10
+    suggesters: [ontotext-suggestion-api]
+    skipFilters: [broader]
+`), 0644))
+
+	c, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, Config{Name: "ontotext-only", Variants: []VariantConfig{
+		{Name: "control", Weight: 90},
+		{Name: "without-authors", Weight: 10, Suggesters: []string{"ontotext-suggestion-api"}, SkipFilters: []string{"broader"}},
+	}}, c)
+
+	ontotext := service.NewOntotextSuggester("http://ontotext", "/suggest", nil)
+	e, err := c.Build(map[string]service.Suggester{"ontotext-suggestion-api": ontotext})
+	require.NoError(t, err)
+	assert.Equal(t, "ontotext-only", e.Name)
+	require.Len(t, e.Variants, 2)
+	assert.Nil(t, e.Variants[0].Suggesters)
+	assert.Equal(t, []service.Suggester{ontotext}, e.Variants[1].Suggesters)
+	assert.Equal(t, []string{service.FilterBroader}, e.Variants[1].SkipFilters)
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	_, err := LoadFile(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.True(t, os.IsNotExist(err))
+
+	path := filepath.Join(t.TempDir(), "experiment.yml")
+	require.NoError(t, os.WriteFile(path, []byte("variants: {"), 0644))
+	_, err = LoadFile(path)
+	assert.Error(t, err)
+}
+
+func TestConfig_BuildUnknownSuggester(t *testing.T) {
+	c := Config{Name: "e", Variants: []VariantConfig{{Name: "v", Weight: 1, Suggesters: []string{"nope"}}}}
+	_, err := c.Build(map[string]service.Suggester{"authors-suggestion-api": nil, "ontotext-suggestion-api": nil})
+	assert.EqualError(t, err, `variant v uses unknown suggester "nope", known suggesters are authors-suggestion-api, ontotext-suggestion-api`)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/fakes"
+	cli "github.com/jawher/mow.cli"
+)
+
+const fakesDescription = "Serve the downstream services from fixtures, so the service can run offline with every base URL set to the fakes"
+
+func fakesCommand(cmd *cli.Cmd, log *logger.UPPLogger) {
+	cmd.Spec = "[--fixtures] [--port]"
+
+	fixturesFile := cmd.String(cli.StringOpt{
+		Name:  "fixtures",
+		Value: "_ft/fakes.yml",
+		Desc:  "YAML file with the responses of the downstream services",
+	})
+	port := cmd.String(cli.StringOpt{
+		Name:  "port",
+		Value: "9000",
+		Desc:  "Port to listen on",
+	})
+
+	cmd.Action = func() {
+		fixtures, err := fakes.LoadFile(*fixturesFile)
+		if err != nil {
+			log.WithError(err).Fatal("Could not load the fixtures")
+		}
+		log.Infof("Serving %s on port %s", strings.Join(fixtures.Paths(), ", "), *port)
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		if err := serveFakes(ctx, ":"+*port, fixtures); err != nil {
+			log.WithError(err).Error("The fakes stopped")
+			cli.Exit(1)
+		}
+	}
+}
+
+// serveFakes serves the fixtures until the context is done.
+func serveFakes(ctx context.Context, addr string, fixtures *fakes.Fixtures) error {
+	server := &http.Server{Addr: addr, Handler: fixtures}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
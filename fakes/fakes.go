@@ -0,0 +1,201 @@
+// Package fakes serves canned responses for the downstream services, so the service can run without them.
+package fakes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures are the responses of the fake endpoints, by path then by lower case method.
+// The format is the ersatz fixtures format, with the optional latency, drop, script and keyedBy fields.
+type Fixtures struct {
+	Version  string                          `yaml:"version"`
+	Fixtures map[string]map[string]*Endpoint `yaml:"fixtures"`
+}
+
+// Response is a canned response.
+type Response struct {
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers"`
+	// Body is written as JSON, unless it is a string.
+	Body interface{} `yaml:"body"`
+	// Latency delays the response, e.g. 250ms.
+	Latency string `yaml:"latency"`
+	// Drop closes the connection without responding.
+	Drop bool `yaml:"drop"`
+
+	latency time.Duration
+}
+
+// Endpoint serves the responses of its script in turn, then its own response to every request.
+type Endpoint struct {
+	Response `yaml:",inline"`
+	Script   []Response `yaml:"script"`
+	// KeyedBy is a query parameter, e.g. ids, that selects the entries of the maps of the body, e.g. the concepts
+	// of a concordance response, like the real services only return the requested concepts.
+	KeyedBy string `yaml:"keyedBy"`
+
+	mu     sync.Mutex
+	served int
+}
+
+// LoadFile reads the fixtures from a YAML file.
+func LoadFile(path string) (*Fixtures, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse reads the fixtures from YAML and checks them.
+func Parse(data []byte) (*Fixtures, error) {
+	var f Fixtures
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid fixtures: %w", err)
+	}
+	if len(f.Fixtures) == 0 {
+		return nil, fmt.Errorf("invalid fixtures: no endpoints")
+	}
+	for path, methods := range f.Fixtures {
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid fixtures: path %q does not start with /", path)
+		}
+		for method, e := range methods {
+			if e == nil {
+				return nil, fmt.Errorf("invalid fixtures: %s %s has no response", strings.ToUpper(method), path)
+			}
+			if err := e.Response.validate(); err != nil {
+				return nil, fmt.Errorf("invalid fixtures: %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			for i := range e.Script {
+				if err := e.Script[i].validate(); err != nil {
+					return nil, fmt.Errorf("invalid fixtures: %s %s script %d: %w", strings.ToUpper(method), path, i, err)
+				}
+			}
+		}
+	}
+	return &f, nil
+}
+
+func (r *Response) validate() error {
+	if r.Status == 0 {
+		r.Status = http.StatusOK
+	}
+	if r.Status < 100 || r.Status > 599 {
+		return fmt.Errorf("status %d is not an HTTP status", r.Status)
+	}
+	if r.Latency != "" {
+		d, err := time.ParseDuration(r.Latency)
+		if err != nil || d < 0 {
+			return fmt.Errorf("latency %q is not a duration", r.Latency)
+		}
+		r.latency = d
+	}
+	return nil
+}
+
+// Paths returns the paths of the fixtures, sorted.
+func (f *Fixtures) Paths() []string {
+	paths := make([]string, 0, len(f.Fixtures))
+	for path := range f.Fixtures {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ServeHTTP serves the response of the endpoint of the request path and method, or 404.
+func (f *Fixtures) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	e, ok := f.Fixtures[req.URL.Path][strings.ToLower(req.Method)]
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": fmt.Sprintf("no fixture for %s %s", req.Method, req.URL.Path)})
+		return
+	}
+	e.serve(w, req)
+}
+
+func (e *Endpoint) next() Response {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	r := e.Response
+	if e.served < len(e.Script) {
+		r = e.Script[e.served]
+	}
+	e.served++
+	return r
+}
+
+func (e *Endpoint) serve(w http.ResponseWriter, req *http.Request) {
+	r := e.next()
+	if r.latency > 0 {
+		timer := time.NewTimer(r.latency)
+		defer timer.Stop()
+		select {
+		case <-req.Context().Done():
+			return
+		case <-timer.C:
+		}
+	}
+	if r.Drop {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	var body []byte
+	switch b := r.Body.(type) {
+	case nil:
+	case string:
+		body = []byte(b)
+	default:
+		if e.KeyedBy != "" {
+			b = selectEntries(b, req.URL.Query()[e.KeyedBy])
+		}
+		body, _ = json.Marshal(b)
+		w.Header().Set("Content-Type", "application/json")
+	}
+	for name, value := range r.Headers {
+		w.Header().Set(name, value)
+	}
+	w.WriteHeader(r.Status)
+	w.Write(body)
+}
+
+// selectEntries keeps the entries of the maps of the body whose key is one of keys.
+func selectEntries(body interface{}, keys []string) interface{} {
+	fields, ok := body.(map[string]interface{})
+	if !ok {
+		return body
+	}
+	selected := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			selected[name] = value
+			continue
+		}
+		kept := map[string]interface{}{}
+		for _, key := range keys {
+			if entry, ok := entries[key]; ok {
+				kept[key] = entry
+			}
+		}
+		selected[name] = kept
+	}
+	return selected
+}
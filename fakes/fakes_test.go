@@ -0,0 +1,141 @@
+package fakes
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFixtures = `
+version: "1.0.0"
+fixtures:
+  /blacklist:
+    get:
+      body:
+        uuids: [a]
+  /content/suggest/ontotext:
+    post:
+      script:
+        - status: 503
+        - status: 400
+          body: '{"message":"bad"}'
+          headers:
+            content-type: application/json
+      status: 204
+  /internalconcordances:
+    get:
+      keyedBy: ids
+      body:
+        concepts:
+          a: {id: a}
+          b: {id: b}
+  /slow:
+    get:
+      latency: 1s
+  /dropped:
+    get:
+      drop: true
+`
+
+func get(t *testing.T, server *httptest.Server, method, path string) (int, string, http.Header) {
+	req, err := http.NewRequest(method, server.URL+path, nil)
+	require.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body), resp.Header
+}
+
+func TestFixtures_ServeHTTP(t *testing.T) {
+	fixtures, err := Parse([]byte(testFixtures))
+	require.NoError(t, err)
+	server := httptest.NewServer(fixtures)
+	defer server.Close()
+
+	status, body, header := get(t, server, http.MethodGet, "/blacklist")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"uuids":["a"]}`, body)
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+
+	status, body, _ = get(t, server, http.MethodGet, "/internalconcordances?ids=b&ids=c&include_deprecated=false")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"concepts":{"b":{"id":"b"}}}`, body)
+
+	status, body, _ = get(t, server, http.MethodGet, "/content/suggest/ontotext")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.JSONEq(t, `{"message":"no fixture for GET /content/suggest/ontotext"}`, body)
+}
+
+func TestFixtures_Script(t *testing.T) {
+	fixtures, err := Parse([]byte(testFixtures))
+	require.NoError(t, err)
+	server := httptest.NewServer(fixtures)
+	defer server.Close()
+
+	status, _, _ := get(t, server, http.MethodPost, "/content/suggest/ontotext")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	status, body, header := get(t, server, http.MethodPost, "/content/suggest/ontotext")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, `{"message":"bad"}`, body)
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	for i := 0; i < 2; i++ {
+		status, body, _ = get(t, server, http.MethodPost, "/content/suggest/ontotext")
+		assert.Equal(t, http.StatusNoContent, status)
+		assert.Empty(t, body)
+	}
+}
+
+func TestFixtures_Latency(t *testing.T) {
+	fixtures, err := Parse([]byte(testFixtures))
+	require.NoError(t, err)
+	server := httptest.NewServer(fixtures)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/slow", nil)
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = server.Client().Do(req.WithContext(ctx))
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestFixtures_Drop(t *testing.T) {
+	fixtures, err := Parse([]byte(testFixtures))
+	require.NoError(t, err)
+	server := httptest.NewServer(fixtures)
+	defer server.Close()
+
+	_, err = server.Client().Get(server.URL + "/dropped")
+	assert.Error(t, err)
+}
+
+func TestParse_Errors(t *testing.T) {
+	testCases := []struct {
+		fixtures string
+		err      string
+	}{
+		{fixtures: `fixtures: [`, err: "invalid fixtures: yaml"},
+		{fixtures: `version: "1.0.0"`, err: "invalid fixtures: no endpoints"},
+		{fixtures: `fixtures: {blacklist: {get: {status: 200}}}`, err: `invalid fixtures: path "blacklist" does not start with /`},
+		{fixtures: `fixtures: {/blacklist: {get: }}`, err: "invalid fixtures: GET /blacklist has no response"},
+		{fixtures: `fixtures: {/blacklist: {get: {status: 42}}}`, err: "invalid fixtures: GET /blacklist: status 42 is not an HTTP status"},
+		{fixtures: `fixtures: {/blacklist: {get: {script: [{latency: soon}]}}}`, err: `invalid fixtures: GET /blacklist script 0: latency "soon" is not a duration`},
+	}
+	for _, tc := range testCases {
+		_, err := Parse([]byte(tc.fixtures))
+		if assert.Error(t, err, tc.fixtures) {
+			assert.True(t, strings.HasPrefix(err.Error(), tc.err), err.Error())
+		}
+	}
+}
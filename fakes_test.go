@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/fakes"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakesFixtures(t *testing.T) {
+	fixtures, err := fakes.LoadFile("_ft/fakes.yml")
+	require.NoError(t, err)
+	server := httptest.NewServer(fixtures)
+	defer server.Close()
+
+	content, err := service.NewContentAPI(server.URL, "/content", server.Client()).
+		GetContent(context.Background(), "3b4bd8f2-1e3c-4d8a-9f2b-5c6d7e8f9a0b", "tid_test", "tests")
+	require.NoError(t, err)
+
+	suggester := service.NewAggregateSuggester(logger.NewUPPLogger("test", "panic"),
+		service.NewConcordance(server.URL, "/internalconcordances", server.Client()),
+		service.NewBroaderConceptsProvider(server.URL, "/things", server.Client()),
+		service.NewConceptBlacklister(server.URL, "/blacklist", server.Client()),
+		service.NewAuthorsSuggester(server.URL, "/content/suggest/authors", server.Client()),
+		service.NewOntotextSuggester(server.URL, "/content/suggest/ontotext", server.Client()))
+	response, err := suggester.GetSuggestions(context.Background(), content, "tid_test", "tests")
+	require.NoError(t, err)
+
+	var labels []string
+	for _, s := range response.Suggestions {
+		labels = append(labels, s.PrefLabel)
+	}
+	// London is blacklisted
+	assert.ElementsMatch(t, []string{"Adam Samson", "Michael Hunter", "Eric Platt", "Apple"}, labels)
+}
+
+func TestServeFakes(t *testing.T) {
+	fixtures, err := fakes.Parse([]byte(`fixtures: {/__gtg: {get: {status: 200}}}`))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, serveFakes(ctx, "127.0.0.1:0", fixtures))
+	assert.Error(t, serveFakes(context.Background(), "127.0.0.1:-1", fixtures))
+}
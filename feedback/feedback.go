@@ -0,0 +1,104 @@
+// Package feedback records the decisions of the editors about the suggested concepts
+// and computes the acceptance rates of the suggestions.
+package feedback
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	Accepted = "accepted"
+	Rejected = "rejected"
+
+	Unknown = "unknown"
+)
+
+// Record is the decision of an editor about one suggested concept of a piece of content.
+type Record struct {
+	Time          time.Time `json:"time"`
+	TransactionID string    `json:"transactionId,omitempty"`
+	ContentUUID   string    `json:"contentUuid"`
+	ContentType   string    `json:"contentType,omitempty"`
+	ConceptID     string    `json:"conceptId"`
+	Predicate     string    `json:"predicate,omitempty"`
+	ConceptType   string    `json:"conceptType"`
+	Source        string    `json:"source"`
+	Origin        string    `json:"origin,omitempty"`
+	Decision      string    `json:"decision"`
+}
+
+// Validate checks the record can be stored.
+func (r Record) Validate() error {
+	if r.ConceptID == "" {
+		return errors.New("concept id is missing")
+	}
+	if r.Decision != Accepted && r.Decision != Rejected {
+		return fmt.Errorf("decision %q is neither %s nor %s", r.Decision, Accepted, Rejected)
+	}
+	return nil
+}
+
+// Store persists the records.
+type Store interface {
+	Add(records ...Record) error
+	// Since calls fn with the records of at least since, oldest first, until fn returns an error.
+	Since(since time.Time, fn func(Record) error) error
+	Close() error
+}
+
+// Rate counts the decisions about a group of suggestions.
+type Rate struct {
+	Accepted       int     `json:"accepted"`
+	Rejected       int     `json:"rejected"`
+	AcceptanceRate float64 `json:"acceptanceRate"`
+}
+
+func (r *Rate) add(decision string) {
+	if decision == Accepted {
+		r.Accepted++
+	} else {
+		r.Rejected++
+	}
+	r.AcceptanceRate = float64(r.Accepted) / float64(r.Accepted+r.Rejected)
+}
+
+// Rates are the acceptance rates of the suggestions since a point in time.
+type Rates struct {
+	Since         time.Time        `json:"since"`
+	Overall       Rate             `json:"overall"`
+	BySource      map[string]*Rate `json:"bySource"`
+	ByConceptType map[string]*Rate `json:"byConceptType"`
+	ByOrigin      map[string]*Rate `json:"byOrigin"`
+}
+
+// AcceptanceRates computes the acceptance rates of the records of the store since the given time.
+func AcceptanceRates(store Store, since time.Time) (Rates, error) {
+	rates := Rates{
+		Since:         since,
+		BySource:      map[string]*Rate{},
+		ByConceptType: map[string]*Rate{},
+		ByOrigin:      map[string]*Rate{},
+	}
+	err := store.Since(since, func(r Record) error {
+		rates.Overall.add(r.Decision)
+		rate(rates.BySource, r.Source).add(r.Decision)
+		rate(rates.ByConceptType, r.ConceptType).add(r.Decision)
+		rate(rates.ByOrigin, r.Origin).add(r.Decision)
+		return nil
+	})
+	return rates, err
+}
+
+func rate(rates map[string]*Rate, key string) *Rate {
+	if key == "" {
+		key = Unknown
+	}
+	r, ok := rates[key]
+	if !ok {
+		r = &Rate{}
+		rates[key] = r
+	}
+	return r
+}
@@ -0,0 +1,97 @@
+package feedback
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openStore(t *testing.T) *BoltStore {
+	store, err := OpenBoltStore(filepath.Join(t.TempDir(), "feedback.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestRecord_Validate(t *testing.T) {
+	assert.NoError(t, Record{ConceptID: "c", Decision: Accepted}.Validate())
+	assert.EqualError(t, Record{Decision: Accepted}.Validate(), "concept id is missing")
+	assert.EqualError(t, Record{ConceptID: "c", Decision: "maybe"}.Validate(), `decision "maybe" is neither accepted nor rejected`)
+}
+
+func TestBoltStore_Since(t *testing.T) {
+	store := openStore(t)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Add(
+		Record{Time: now, ConceptID: "b", Decision: Accepted},
+		Record{Time: now.Add(-time.Hour), ConceptID: "a", Decision: Rejected},
+	))
+	require.NoError(t, store.Add(Record{Time: now, ConceptID: "c", Decision: Accepted}))
+
+	var ids []string
+	collect := func(r Record) error {
+		ids = append(ids, r.ConceptID)
+		return nil
+	}
+	require.NoError(t, store.Since(time.Time{}, collect))
+	assert.Equal(t, []string{"a", "b", "c"}, ids)
+
+	ids = nil
+	require.NoError(t, store.Since(now, collect))
+	assert.Equal(t, []string{"b", "c"}, ids)
+
+	stop := errors.New("stop")
+	assert.Equal(t, stop, store.Since(time.Time{}, func(Record) error { return stop }))
+}
+
+func TestBoltStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feedback.db")
+	store, err := OpenBoltStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Add(Record{Time: time.Now(), ConceptID: "a", Decision: Accepted}))
+	require.NoError(t, store.Close())
+
+	store, err = OpenBoltStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	count := 0
+	require.NoError(t, store.Since(time.Time{}, func(Record) error {
+		count++
+		return nil
+	}))
+	assert.Equal(t, 1, count)
+}
+
+func TestAcceptanceRates(t *testing.T) {
+	store := openStore(t)
+	now := time.Now().UTC()
+	require.NoError(t, store.Add(
+		Record{Time: now.Add(-48 * time.Hour), ConceptID: "old", ConceptType: "Person", Source: "Ontotext Suggestion API", Decision: Rejected},
+		Record{Time: now, ConceptID: "a", ConceptType: "Person", Source: "Ontotext Suggestion API", Origin: "spark", Decision: Accepted},
+		Record{Time: now, ConceptID: "b", ConceptType: "Person", Source: "Ontotext Suggestion API", Origin: "spark", Decision: Rejected},
+		Record{Time: now, ConceptID: "c", ConceptType: "Topic", Source: "Ontotext Suggestion API", Decision: Rejected},
+		Record{Time: now, ConceptID: "d", ConceptType: "Person", Source: "Authors Suggestion API", Origin: "spark", Decision: Accepted},
+	))
+
+	since := now.Add(-24 * time.Hour)
+	rates, err := AcceptanceRates(store, since)
+	require.NoError(t, err)
+	assert.Equal(t, since, rates.Since)
+	assert.Equal(t, Rate{Accepted: 2, Rejected: 2, AcceptanceRate: 0.5}, rates.Overall)
+	assert.Equal(t, map[string]*Rate{
+		"Ontotext Suggestion API": {Accepted: 1, Rejected: 2, AcceptanceRate: 1.0 / 3},
+		"Authors Suggestion API":  {Accepted: 1, AcceptanceRate: 1},
+	}, rates.BySource)
+	assert.Equal(t, map[string]*Rate{
+		"Person": {Accepted: 2, Rejected: 1, AcceptanceRate: 2.0 / 3},
+		"Topic":  {Rejected: 1},
+	}, rates.ByConceptType)
+	assert.Equal(t, map[string]*Rate{
+		"spark":   {Accepted: 2, Rejected: 1, AcceptanceRate: 2.0 / 3},
+		"unknown": {Rejected: 1},
+	}, rates.ByOrigin)
+}
@@ -0,0 +1,146 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	fp "path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+)
+
+// SuppressionConfig sets when a concept is suppressed.
+type SuppressionConfig struct {
+	// Window is how far back the decisions are counted, the suppressions expire with the decisions.
+	Window time.Duration
+	// RejectionRate is the share of rejections from which a concept is suppressed, between 0 and 1.
+	RejectionRate float64
+	// MinDecisions is the number of decisions needed before a concept can be suppressed.
+	MinDecisions int
+}
+
+func (c SuppressionConfig) validate() error {
+	if c.Window <= 0 {
+		return errors.New("suppression window must be positive")
+	}
+	if c.RejectionRate <= 0 || c.RejectionRate > 1 {
+		return fmt.Errorf("suppression rejection rate must be in (0, 1], got %v", c.RejectionRate)
+	}
+	if c.MinDecisions < 1 {
+		return fmt.Errorf("suppression minimum decisions must be at least 1, got %d", c.MinDecisions)
+	}
+	return nil
+}
+
+// Suppressor tells which concepts the editors keep rejecting for an origin or a content type.
+// It works on a snapshot of the store, updated by Refresh.
+type Suppressor struct {
+	store  Store
+	config SuppressionConfig
+	log    *logger.UPPLogger
+	now    func() time.Time
+
+	mu            sync.RWMutex
+	byOrigin      map[suppressionKey]bool
+	byContentType map[suppressionKey]bool
+}
+
+type suppressionKey struct {
+	concept string
+	scope   string
+}
+
+func NewSuppressor(store Store, config SuppressionConfig, log *logger.UPPLogger) (*Suppressor, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &Suppressor{
+		store:         store,
+		config:        config,
+		log:           log,
+		now:           time.Now,
+		byOrigin:      map[suppressionKey]bool{},
+		byContentType: map[suppressionKey]bool{},
+	}, nil
+}
+
+// IsSuppressed reports if the concept is rejected above the rate for the origin or for the content type.
+// Empty origins and content types never match.
+func (s *Suppressor) IsSuppressed(conceptID, origin, contentType string) bool {
+	concept := fp.Base(conceptID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return (origin != "" && s.byOrigin[suppressionKey{concept, origin}]) ||
+		(contentType != "" && s.byContentType[suppressionKey{concept, contentType}])
+}
+
+// Suppressed returns the number of suppressed concept and origin or content type pairs.
+func (s *Suppressor) Suppressed() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.byOrigin) + len(s.byContentType)
+}
+
+// Refresh recomputes the suppressed concepts from the decisions of the window.
+func (s *Suppressor) Refresh() error {
+	type counts struct{ total, rejected int }
+	byOrigin := map[suppressionKey]*counts{}
+	byContentType := map[suppressionKey]*counts{}
+	count := func(m map[suppressionKey]*counts, key suppressionKey, decision string) {
+		if key.scope == "" {
+			return
+		}
+		c, ok := m[key]
+		if !ok {
+			c = &counts{}
+			m[key] = c
+		}
+		c.total++
+		if decision == Rejected {
+			c.rejected++
+		}
+	}
+	err := s.store.Since(s.now().Add(-s.config.Window), func(r Record) error {
+		concept := fp.Base(r.ConceptID)
+		count(byOrigin, suppressionKey{concept, r.Origin}, r.Decision)
+		count(byContentType, suppressionKey{concept, r.ContentType}, r.Decision)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	suppressed := func(m map[suppressionKey]*counts) map[suppressionKey]bool {
+		result := map[suppressionKey]bool{}
+		for key, c := range m {
+			if c.total >= s.config.MinDecisions && float64(c.rejected)/float64(c.total) >= s.config.RejectionRate {
+				result[key] = true
+			}
+		}
+		return result
+	}
+	origins, contentTypes := suppressed(byOrigin), suppressed(byContentType)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byOrigin, s.byContentType = origins, contentTypes
+	return nil
+}
+
+// Run refreshes the suppressed concepts every interval until the context is done.
+func (s *Suppressor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.Refresh(); err != nil {
+			s.log.WithError(err).Error("Could not refresh the suppressed concepts, keeping the previous ones")
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package feedback
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSuppressor_InvalidConfig(t *testing.T) {
+	log := logger.NewUPPLogger("test", "panic")
+	for _, c := range []SuppressionConfig{
+		{RejectionRate: 0.5, MinDecisions: 1},
+		{Window: time.Hour, RejectionRate: 0, MinDecisions: 1},
+		{Window: time.Hour, RejectionRate: 1.5, MinDecisions: 1},
+		{Window: time.Hour, RejectionRate: 0.5},
+	} {
+		_, err := NewSuppressor(nil, c, log)
+		assert.Error(t, err, "%+v", c)
+	}
+}
+
+func TestSuppressor_Refresh(t *testing.T) {
+	store := openStore(t)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	decision := func(age time.Duration, concept, origin, contentType, decision string) Record {
+		return Record{Time: now.Add(-age), ConceptID: "http://www.ft.com/thing/" + concept, Origin: origin, ContentType: contentType, Decision: decision}
+	}
+	require.NoError(t, store.Add(
+		// rejected by spark editors, accepted elsewhere
+		decision(time.Hour, "a", "spark", "", Rejected),
+		decision(time.Hour, "a", "spark", "", Rejected),
+		decision(time.Hour, "a", "spark", "", Accepted),
+		decision(time.Hour, "a", "methode", "", Accepted),
+		// rejected on live blogs, whatever the origin
+		decision(time.Hour, "b", "spark", "LiveBlogPost", Rejected),
+		decision(time.Hour, "b", "methode", "LiveBlogPost", Rejected),
+		decision(time.Hour, "b", "methode", "LiveBlogPost", Rejected),
+		// not enough decisions
+		decision(time.Hour, "c", "spark", "", Rejected),
+		// rejections out of the window have expired
+		decision(48*time.Hour, "d", "spark", "", Rejected),
+		decision(48*time.Hour, "d", "spark", "", Rejected),
+		decision(48*time.Hour, "d", "spark", "", Rejected),
+	))
+
+	s, err := NewSuppressor(store, SuppressionConfig{Window: 24 * time.Hour, RejectionRate: 0.6, MinDecisions: 3}, logger.NewUPPLogger("test", "panic"))
+	require.NoError(t, err)
+	s.now = func() time.Time { return now }
+	assert.False(t, s.IsSuppressed("http://www.ft.com/thing/a", "spark", ""), "nothing is suppressed before the first refresh")
+
+	require.NoError(t, s.Refresh())
+	assert.True(t, s.IsSuppressed("http://www.ft.com/thing/a", "spark", "Article"))
+	assert.True(t, s.IsSuppressed("a", "spark", ""))
+	assert.False(t, s.IsSuppressed("a", "methode", ""))
+	assert.True(t, s.IsSuppressed("b", "other", "LiveBlogPost"))
+	assert.False(t, s.IsSuppressed("b", "methode", "Article"), "only 2 decisions by methode editors")
+	assert.False(t, s.IsSuppressed("b", "spark", "Article"))
+	assert.False(t, s.IsSuppressed("c", "spark", ""))
+	assert.False(t, s.IsSuppressed("d", "spark", ""))
+	assert.False(t, s.IsSuppressed("a", "", ""))
+	assert.Equal(t, 2, s.Suppressed())
+}
+
+func TestSuppressor_Run(t *testing.T) {
+	store := openStore(t)
+	s, err := NewSuppressor(store, SuppressionConfig{Window: time.Hour, RejectionRate: 1, MinDecisions: 1}, logger.NewUPPLogger("test", "panic"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	require.NoError(t, store.Add(Record{Time: time.Now(), ConceptID: "a", Origin: "spark", Decision: Rejected}))
+	assert.Eventually(t, func() bool { return s.IsSuppressed("a", "spark", "") }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
@@ -0,0 +1,104 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var entriesBucket = []byte("history")
+
+// BoltStore keeps the entries in a BoltDB file, with a bucket per content and only the last versions of each content.
+type BoltStore struct {
+	db          *bolt.DB
+	maxVersions int
+}
+
+// OpenBoltStore opens, or creates, the BoltDB file at path. Only the last maxVersions entries of a content are kept.
+func OpenBoltStore(path string, maxVersions int) (*BoltStore, error) {
+	if maxVersions < 1 {
+		return nil, errors.New("history must keep at least one version")
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(entriesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db, maxVersions: maxVersions}, nil
+}
+
+// Add stores the entry, the writes of concurrent requests are batched in the same transaction.
+func (s *BoltStore) Add(entry Entry) error {
+	if entry.ContentUUID == "" {
+		return errors.New("entry has no content uuid")
+	}
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.db.Batch(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(entriesBucket).CreateBucketIfNotExists([]byte(entry.ContentUUID))
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := b.Put(versionKey(seq), value); err != nil {
+			return err
+		}
+		var keys [][]byte
+		b.ForEach(func(k, _ []byte) error {
+			keys = append(keys, k)
+			return nil
+		})
+		// the oldest versions come first
+		for len(keys) > s.maxVersions {
+			if err := b.Delete(keys[0]); err != nil {
+				return err
+			}
+			keys = keys[1:]
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) List(contentUUID string) ([]Entry, error) {
+	entries := []Entry{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(entriesBucket).Bucket([]byte(contentUUID))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func versionKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
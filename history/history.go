@@ -0,0 +1,79 @@
+// Package history keeps the suggestions returned for every piece of content, so past responses can be explained.
+package history
+
+import (
+	"time"
+
+	"github.com/Financial-Times/public-suggestions-api/service"
+)
+
+// Entry is the response returned for a piece of content, with the raw and returned suggestions of every source.
+type Entry struct {
+	Time          time.Time              `json:"time"`
+	TransactionID string                 `json:"transactionId,omitempty"`
+	ContentUUID   string                 `json:"contentUuid"`
+	Origin        string                 `json:"origin,omitempty"`
+	Experiment    string                 `json:"experiment,omitempty"`
+	Variant       string                 `json:"variant,omitempty"`
+	Suggestions   []service.Suggestion   `json:"suggestions"`
+	Sources       []service.SourceReport `json:"sources"`
+}
+
+// Store persists the entries.
+type Store interface {
+	Add(entry Entry) error
+	// List returns the entries of the content, oldest first.
+	List(contentUUID string) ([]Entry, error)
+	Close() error
+}
+
+// Diff is the change of the suggestions between two consecutive entries.
+type Diff struct {
+	Added   []service.Suggestion `json:"added"`
+	Removed []service.Suggestion `json:"removed"`
+}
+
+// Version is an entry with its diff from the previous entry, the first version has no diff.
+type Version struct {
+	Entry
+	Diff *Diff `json:"diff,omitempty"`
+}
+
+// Versions diffs the consecutive entries.
+func Versions(entries []Entry) []Version {
+	versions := make([]Version, 0, len(entries))
+	for i, e := range entries {
+		v := Version{Entry: e}
+		if i > 0 {
+			d := diff(entries[i-1].Suggestions, e.Suggestions)
+			v.Diff = &d
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// diff compares the suggestions by concept and predicate, a concept suggested with another predicate is removed and added.
+func diff(previous, current []service.Suggestion) Diff {
+	type key struct{ id, predicate string }
+	keys := func(suggestions []service.Suggestion) map[key]bool {
+		m := make(map[key]bool, len(suggestions))
+		for _, s := range suggestions {
+			m[key{s.ID, s.Predicate}] = true
+		}
+		return m
+	}
+	before, after := keys(previous), keys(current)
+	d := Diff{Added: []service.Suggestion{}, Removed: []service.Suggestion{}}
+	for _, s := range current {
+		if !before[key{s.ID, s.Predicate}] {
+			d.Added = append(d.Added, s)
+		}
+	}
+	for _, s := range previous {
+		if !after[key{s.ID, s.Predicate}] {
+			d.Removed = append(d.Removed, s)
+		}
+	}
+	return d
+}
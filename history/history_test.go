@@ -0,0 +1,82 @@
+package history
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openStore(t *testing.T, maxVersions int) *BoltStore {
+	store, err := OpenBoltStore(filepath.Join(t.TempDir(), "history.db"), maxVersions)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func suggestion(id, predicate string) service.Suggestion {
+	return service.Suggestion{Concept: service.Concept{ID: id}, Predicate: predicate}
+}
+
+func TestBoltStore_List(t *testing.T) {
+	store := openStore(t, 2)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, tid := range []string{"tid_1", "tid_2", "tid_3"} {
+		require.NoError(t, store.Add(Entry{Time: now.Add(time.Duration(i) * time.Hour), TransactionID: tid, ContentUUID: "content"}))
+	}
+	require.NoError(t, store.Add(Entry{Time: now, TransactionID: "tid_other", ContentUUID: "other"}))
+
+	entries, err := store.List("content")
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "only the last versions are kept")
+	assert.Equal(t, "tid_2", entries[0].TransactionID)
+	assert.Equal(t, "tid_3", entries[1].TransactionID)
+	assert.True(t, now.Add(2*time.Hour).Equal(entries[1].Time))
+
+	entries, err = store.List("unknown")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestBoltStore_AddConcurrently(t *testing.T) {
+	store := openStore(t, 100)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, store.Add(Entry{ContentUUID: "content"}))
+		}()
+	}
+	wg.Wait()
+	entries, err := store.List("content")
+	require.NoError(t, err)
+	assert.Len(t, entries, 20)
+}
+
+func TestBoltStore_Errors(t *testing.T) {
+	_, err := OpenBoltStore(filepath.Join(t.TempDir(), "history.db"), 0)
+	assert.Error(t, err)
+	assert.Error(t, openStore(t, 1).Add(Entry{}), "entries need a content uuid")
+}
+
+func TestVersions(t *testing.T) {
+	a, b, c := suggestion("a", "about"), suggestion("b", "mentions"), suggestion("c", "mentions")
+	versions := Versions([]Entry{
+		{TransactionID: "tid_1", Suggestions: []service.Suggestion{a, b}},
+		{TransactionID: "tid_2", Suggestions: []service.Suggestion{a, b}},
+		{TransactionID: "tid_3", Suggestions: []service.Suggestion{suggestion("a", "mentions"), c}},
+	})
+	require.Len(t, versions, 3)
+	assert.Nil(t, versions[0].Diff)
+	assert.Equal(t, &Diff{Added: []service.Suggestion{}, Removed: []service.Suggestion{}}, versions[1].Diff)
+	assert.Equal(t, &Diff{
+		Added:   []service.Suggestion{suggestion("a", "mentions"), c},
+		Removed: []service.Suggestion{a, b},
+	}, versions[2].Diff)
+	assert.Empty(t, Versions(nil))
+}
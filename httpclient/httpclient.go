@@ -0,0 +1,263 @@
+// Package httpclient builds the HTTP clients used to call the downstream
+// services, so that each downstream can get its own timeouts, connection pool,
+// TLS and proxy settings.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ProxyNone disables proxying, which is the default.
+	ProxyNone = "none"
+	// ProxyEnvironment takes the proxy from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	ProxyEnvironment = "environment"
+)
+
+// Config holds the settings of a single downstream HTTP client.
+type Config struct {
+	Timeout               time.Duration
+	DialTimeout           time.Duration
+	KeepAlive             time.Duration
+	DisableKeepAlives     bool
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int
+	IdleConnTimeout       time.Duration
+	TLSHandshakeTimeout   time.Duration
+	TLSInsecureSkipVerify bool
+	TLSCAFile             string
+	Proxy                 string
+}
+
+// DefaultConfig returns the settings that were historically shared by all downstream clients.
+func DefaultConfig() Config {
+	return Config{
+		Timeout:             10 * time.Second,
+		DialTimeout:         10 * time.Second,
+		KeepAlive:           30 * time.Second,
+		MaxIdleConnsPerHost: 128,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		Proxy:               ProxyNone,
+	}
+}
+
+// Set updates a single setting by its key, e.g. Set("timeout", "5s").
+func (c *Config) Set(key, value string) error {
+	value = strings.TrimSpace(value)
+	var err error
+	switch strings.TrimSpace(key) {
+	case "timeout":
+		c.Timeout, err = time.ParseDuration(value)
+	case "dial-timeout":
+		c.DialTimeout, err = time.ParseDuration(value)
+	case "keep-alive":
+		c.KeepAlive, err = time.ParseDuration(value)
+	case "disable-keep-alives":
+		c.DisableKeepAlives, err = strconv.ParseBool(value)
+	case "max-idle-conns":
+		c.MaxIdleConns, err = strconv.Atoi(value)
+	case "max-idle-conns-per-host":
+		c.MaxIdleConnsPerHost, err = strconv.Atoi(value)
+	case "max-conns-per-host":
+		c.MaxConnsPerHost, err = strconv.Atoi(value)
+	case "idle-conn-timeout":
+		c.IdleConnTimeout, err = time.ParseDuration(value)
+	case "tls-handshake-timeout":
+		c.TLSHandshakeTimeout, err = time.ParseDuration(value)
+	case "tls-insecure-skip-verify":
+		c.TLSInsecureSkipVerify, err = strconv.ParseBool(value)
+	case "tls-ca-file":
+		c.TLSCAFile = value
+	case "proxy":
+		c.Proxy = value
+	default:
+		return fmt.Errorf("unknown http client setting %q", key)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q for http client setting %q: %w", value, key, err)
+	}
+	return nil
+}
+
+// Apply updates the settings from a comma separated list of key=value pairs,
+// e.g. "timeout=5s,max-conns-per-host=64". An empty spec leaves the settings untouched.
+func (c *Config) Apply(spec string) error {
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid http client setting %q, expected key=value", pair)
+		}
+		if err := c.Set(kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileConfig is the content of an http client configuration file.
+//
+// Default settings are applied to every downstream, the Downstreams entries are applied on top of them.
+//
+//	default:
+//	  timeout: 10s
+//	downstreams:
+//	  ontotext-suggestion-api:
+//	    timeout: 5s
+//	    max-conns-per-host: 32
+type FileConfig struct {
+	Default     map[string]interface{}            `yaml:"default"`
+	Downstreams map[string]map[string]interface{} `yaml:"downstreams"`
+}
+
+// LoadFile reads an http client configuration file in YAML (or JSON) format. Its downstreams must be some of the
+// given ones, so that a misspelt downstream does not silently leave the defaults in place.
+func LoadFile(path string, downstreams []string) (FileConfig, error) {
+	var fc FileConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fc, err
+	}
+	if err = yaml.Unmarshal(data, &fc); err != nil {
+		return fc, fmt.Errorf("parsing http client configuration %s: %w", path, err)
+	}
+	known := map[string]bool{}
+	for _, d := range downstreams {
+		known[d] = true
+	}
+	names := make([]string, 0, len(fc.Downstreams))
+	for name := range fc.Downstreams {
+		names = append(names, name)
+	}
+	// sorted for deterministic error reporting
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			sorted := append([]string(nil), downstreams...)
+			sort.Strings(sorted)
+			return fc, fmt.Errorf("http client configuration %s has unknown downstream %q, known downstreams are %s", path, name, strings.Join(sorted, ", "))
+		}
+	}
+	return fc, nil
+}
+
+// ConfigFor resolves the settings of the named downstream.
+//
+// The precedence, from lowest to highest, is: DefaultConfig, the file defaults,
+// the file entry for the downstream and finally the spec given through CLI flags or env vars.
+func (fc FileConfig) ConfigFor(downstream, spec string) (Config, error) {
+	cfg := DefaultConfig()
+	if err := applyMap(&cfg, fc.Default); err != nil {
+		return cfg, fmt.Errorf("default http client configuration: %w", err)
+	}
+	if err := applyMap(&cfg, fc.Downstreams[downstream]); err != nil {
+		return cfg, fmt.Errorf("%s http client configuration: %w", downstream, err)
+	}
+	if err := cfg.Apply(spec); err != nil {
+		return cfg, fmt.Errorf("%s http client configuration: %w", downstream, err)
+	}
+	return cfg, nil
+}
+
+func applyMap(cfg *Config, settings map[string]interface{}) error {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	// sorted for deterministic error reporting
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := cfg.Set(k, fmt.Sprint(settings[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// New creates an http.Client with its own transport from the given settings.
+func New(cfg Config) (*http.Client, error) {
+	if cfg.Timeout < 0 || cfg.DialTimeout < 0 || cfg.IdleConnTimeout < 0 || cfg.TLSHandshakeTimeout < 0 {
+		return nil, errors.New("http client timeouts must not be negative")
+	}
+	if cfg.MaxIdleConns < 0 || cfg.MaxIdleConnsPerHost < 0 || cfg.MaxConnsPerHost < 0 {
+		return nil, errors.New("http client connection limits must not be negative")
+	}
+
+	proxy, err := proxyFunc(cfg.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tlsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: proxy,
+			DialContext: (&net.Dialer{
+				Timeout:   cfg.DialTimeout,
+				KeepAlive: cfg.KeepAlive,
+			}).DialContext,
+			DisableKeepAlives:   cfg.DisableKeepAlives,
+			MaxIdleConns:        cfg.MaxIdleConns,
+			MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     cfg.MaxConnsPerHost,
+			IdleConnTimeout:     cfg.IdleConnTimeout,
+			TLSHandshakeTimeout: cfg.TLSHandshakeTimeout,
+			TLSClientConfig:     tlsConfig,
+		},
+		Timeout: cfg.Timeout,
+	}, nil
+}
+
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch proxy {
+	case "", ProxyNone:
+		return nil, nil
+	case ProxyEnvironment:
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", proxy)
+	}
+	return http.ProxyURL(u), nil
+}
+
+func tlsConfig(cfg Config) (*tls.Config, error) {
+	if !cfg.TLSInsecureSkipVerify && cfg.TLSCAFile == "" {
+		return nil, nil
+	}
+	// #nosec G402 -- skipping verification is an explicit opt-in for non-production downstreams
+	tc := &tls.Config{InsecureSkipVerify: cfg.TLSInsecureSkipVerify}
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.TLSCAFile)
+		}
+		tc.RootCAs = pool
+	}
+	return tc, nil
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Apply(t *testing.T) {
+	cfg := DefaultConfig()
+	err := cfg.Apply("timeout=5s, max-conns-per-host=64,disable-keep-alives=true,proxy=environment")
+	require.NoError(t, err)
+
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, 64, cfg.MaxConnsPerHost)
+	assert.True(t, cfg.DisableKeepAlives)
+	assert.Equal(t, ProxyEnvironment, cfg.Proxy)
+	assert.Equal(t, 128, cfg.MaxIdleConnsPerHost, "untouched settings keep their defaults")
+}
+
+func TestConfig_ApplyErrors(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expectedErr string
+	}{
+		{"timeout", `invalid http client setting "timeout", expected key=value`},
+		{"colour=blue", `unknown http client setting "colour"`},
+		{"timeout=fast", `invalid value "fast" for http client setting "timeout"`},
+		{"max-idle-conns=many", `invalid value "many" for http client setting "max-idle-conns"`},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			cfg := DefaultConfig()
+			err := cfg.Apply(test.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestFileConfig_ConfigFor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.yml")
+	err := os.WriteFile(path, []byte(`
+default:
+  timeout: 8s
+  max-idle-conns-per-host: 64
+downstreams:
+  ontotext-suggestion-api:
+    timeout: 3s
+    max-conns-per-host: 16
+`), 0600)
+	require.NoError(t, err)
+
+	fc, err := LoadFile(path, []string{"ontotext-suggestion-api", "internal-concordances-api"})
+	require.NoError(t, err)
+
+	ontotext, err := fc.ConfigFor("ontotext-suggestion-api", "")
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, ontotext.Timeout)
+	assert.Equal(t, 16, ontotext.MaxConnsPerHost)
+	assert.Equal(t, 64, ontotext.MaxIdleConnsPerHost)
+
+	concordances, err := fc.ConfigFor("internal-concordances-api", "timeout=2s")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, concordances.Timeout, "flag spec takes precedence over the file")
+	assert.Equal(t, 0, concordances.MaxConnsPerHost)
+	assert.Equal(t, 64, concordances.MaxIdleConnsPerHost)
+}
+
+func TestLoadFile_Missing(t *testing.T) {
+	_, err := LoadFile(filepath.Join(t.TempDir(), "missing.yml"), nil)
+	assert.Error(t, err)
+}
+
+func TestLoadFile_UnknownDownstream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+downstreams:
+  ontotext:
+    timeout: 3s
+`), 0600))
+
+	_, err := LoadFile(path, []string{"ontotext-suggestion-api", "authors-suggestion-api"})
+	assert.EqualError(t, err, `http client configuration `+path+` has unknown downstream "ontotext", known downstreams are authors-suggestion-api, ontotext-suggestion-api`)
+}
+
+func TestNew(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, cfg.Apply("timeout=4s,max-conns-per-host=8,proxy=http://proxy.example:3128"))
+
+	c, err := New(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 4*time.Second, c.Timeout)
+
+	tr := c.Transport.(*http.Transport)
+	assert.Equal(t, 8, tr.MaxConnsPerHost)
+	assert.Equal(t, 128, tr.MaxIdleConnsPerHost)
+	assert.Nil(t, tr.TLSClientConfig)
+
+	proxy, err := tr.Proxy(&http.Request{URL: &url.URL{Scheme: "http", Host: "ontotext"}})
+	require.NoError(t, err)
+	assert.Equal(t, "proxy.example:3128", proxy.Host)
+}
+
+func TestNew_SeparateTransports(t *testing.T) {
+	a, err := New(DefaultConfig())
+	require.NoError(t, err)
+	b, err := New(DefaultConfig())
+	require.NoError(t, err)
+	assert.True(t, a.Transport != b.Transport, "each client should get its own transport")
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"negative timeout", "timeout=-1s"},
+		{"negative pool", "max-conns-per-host=-1"},
+		{"bad proxy", "proxy=not a url"},
+		{"missing CA file", "tls-ca-file=/does/not/exist.pem"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			require.NoError(t, cfg.Apply(test.spec))
+			_, err := New(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNew_InsecureSkipVerify(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, cfg.Apply("tls-insecure-skip-verify=true"))
+	c, err := New(cfg)
+	require.NoError(t, err)
+	assert.True(t, c.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify)
+}
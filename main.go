@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
-	"net"
+	_ "embed"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -13,16 +18,41 @@ import (
 	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/Financial-Times/http-handlers-go/v2/httphandlers"
+	"github.com/Financial-Times/public-suggestions-api/audit"
+	"github.com/Financial-Times/public-suggestions-api/content"
+	"github.com/Financial-Times/public-suggestions-api/evaluate"
+	"github.com/Financial-Times/public-suggestions-api/experiment"
+	"github.com/Financial-Times/public-suggestions-api/feedback"
+	"github.com/Financial-Times/public-suggestions-api/history"
+	"github.com/Financial-Times/public-suggestions-api/httpclient"
+	"github.com/Financial-Times/public-suggestions-api/monitoring"
+	"github.com/Financial-Times/public-suggestions-api/projection"
 	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/Financial-Times/public-suggestions-api/tracing"
 	"github.com/Financial-Times/public-suggestions-api/web"
+	"github.com/Financial-Times/public-suggestions-api/worker"
+	"github.com/Financial-Times/service-status-go/buildinfo"
 	status "github.com/Financial-Times/service-status-go/httphandlers"
 	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-version"
 	cli "github.com/jawher/mow.cli"
 	"github.com/rcrowley/go-metrics"
 )
 
 const appDescription = "Service serving requests made towards suggestions umbrella"
 const suggestPath = "/content/suggest"
+const storedContentSuggestPath = "/content/{uuid}/suggest"
+
+const (
+	suppressionModeNone     = "none"
+	suppressionModeSuppress = "suppress"
+	suppressionModeDemote   = "demote"
+)
+
+// apiDocument is the spec served at /__api.
+//
+//go:embed _ft/api.yml
+var apiDocument []byte
 
 func main() {
 	app := cli.App("public-suggestions-api", appDescription)
@@ -63,6 +93,12 @@ func main() {
 		Desc:   "The endpoint for authors suggestion api",
 		EnvVar: "AUTHORS_SUGGESTION_ENDPOINT",
 	})
+	authorsSuggestionHTTPClient := app.String(cli.StringOpt{
+		Name:   "authors-suggestion-api-http-client",
+		Value:  "",
+		Desc:   "Comma separated key=value HTTP client settings for authors suggestion api, e.g. timeout=5s,max-conns-per-host=64",
+		EnvVar: "AUTHORS_SUGGESTION_API_HTTP_CLIENT",
+	})
 	ontotextSuggestionApiBaseURL := app.String(cli.StringOpt{
 		Name:   "ontotext-suggestion-api-base-url",
 		Value:  "http://ontotext-suggestion-api:8080",
@@ -75,6 +111,12 @@ func main() {
 		Desc:   "The endpoint for ontotext suggestion api",
 		EnvVar: "ONTOTEXT_SUGGESTION_ENDPOINT",
 	})
+	ontotextSuggestionHTTPClient := app.String(cli.StringOpt{
+		Name:   "ontotext-suggestion-api-http-client",
+		Value:  "",
+		Desc:   "Comma separated key=value HTTP client settings for ontotext suggestion api, e.g. timeout=5s,max-conns-per-host=64",
+		EnvVar: "ONTOTEXT_SUGGESTION_API_HTTP_CLIENT",
+	})
 
 	internalConcordancesApiBaseURL := app.String(cli.StringOpt{
 		Name:   "internal-concordances-api-base-url",
@@ -88,6 +130,12 @@ func main() {
 		Desc:   "The endpoint for internal concordances api",
 		EnvVar: "CONCEPT_CONCORDANCES_ENDPOINT",
 	})
+	internalConcordancesHTTPClient := app.String(cli.StringOpt{
+		Name:   "internal-concordances-api-http-client",
+		Value:  "",
+		Desc:   "Comma separated key=value HTTP client settings for internal concordances api, e.g. timeout=5s,max-conns-per-host=64",
+		EnvVar: "CONCEPT_CONCORDANCES_API_HTTP_CLIENT",
+	})
 
 	publicThingsAPIBaseURL := app.String(cli.StringOpt{
 		Name:   "public-things-api-base-url",
@@ -101,6 +149,12 @@ func main() {
 		Desc:   "The endpoint for public things api",
 		EnvVar: "PUBLIC_THINGS_ENDPOINT",
 	})
+	publicThingsHTTPClient := app.String(cli.StringOpt{
+		Name:   "public-things-api-http-client",
+		Value:  "",
+		Desc:   "Comma separated key=value HTTP client settings for public things api, e.g. timeout=5s,max-conns-per-host=64",
+		EnvVar: "PUBLIC_THINGS_API_HTTP_CLIENT",
+	})
 
 	conceptBlacklisterBaseUrl := app.String(cli.StringOpt{
 		Name:   "concept-blacklister-base-url",
@@ -114,32 +168,409 @@ func main() {
 		Desc:   "The endpoint for concept suggester blacklister",
 		EnvVar: "CONCEPT_BLACKLISTER_ENDPOINT",
 	})
+	conceptBlacklisterHTTPClient := app.String(cli.StringOpt{
+		Name:   "concept-blacklister-http-client",
+		Value:  "",
+		Desc:   "Comma separated key=value HTTP client settings for concept suggester blacklister, e.g. timeout=5s,max-conns-per-host=64",
+		EnvVar: "CONCEPT_BLACKLISTER_HTTP_CLIENT",
+	})
+	contentAPIBaseURL := app.String(cli.StringOpt{
+		Name:   "content-api-base-url",
+		Value:  "http://content-public-read:8080",
+		Desc:   "The base URL to the content read API, used to suggest annotations for stored content",
+		EnvVar: "CONTENT_API_BASE_URL",
+	})
+	contentAPIEndpoint := app.String(cli.StringOpt{
+		Name:   "content-api-endpoint",
+		Value:  "/content",
+		Desc:   "The endpoint for the content read API, the content UUID is appended to it",
+		EnvVar: "CONTENT_API_ENDPOINT",
+	})
+	contentAPIHTTPClient := app.String(cli.StringOpt{
+		Name:   "content-api-http-client",
+		Value:  "",
+		Desc:   "Comma separated key=value HTTP client settings for the content read API, e.g. timeout=5s,max-conns-per-host=64",
+		EnvVar: "CONTENT_API_HTTP_CLIENT",
+	})
+	contentDir := app.String(cli.StringOpt{
+		Name:   "content-dir",
+		Value:  "",
+		Desc:   "A directory of <uuid>.json files read instead of the content read API, e.g. for local testing",
+		EnvVar: "CONTENT_DIR",
+	})
+	shadowSuggesters := app.Strings(cli.StringsOpt{
+		Name:   "shadow-suggesters",
+		Value:  []string{},
+		Desc:   "Suggestion APIs on trial, as name=suggest URL, e.g. ontotext-v2=http://ontotext-v2:8080/content/suggest/ontotext. Their suggestions are only compared with the response",
+		EnvVar: "SHADOW_SUGGESTERS",
+	})
+	experimentConfigFile := app.String(cli.StringOpt{
+		Name:   "experiment-config",
+		Value:  "",
+		Desc:   "Path to a YAML file with the variants and traffic split of an A/B experiment",
+		EnvVar: "EXPERIMENT_CONFIG",
+	})
+	projectionConfigFile := app.String(cli.StringOpt{
+		Name:   "payload-projection-config",
+		Value:  "",
+		Desc:   "Path to a YAML file with the fields, or the template, of the payload each suggester receives. The suggesters without a projection receive the whole payload",
+		EnvVar: "PAYLOAD_PROJECTION_CONFIG",
+	})
+	httpClientConfigFile := app.String(cli.StringOpt{
+		Name:   "http-client-config",
+		Value:  "",
+		Desc:   "Path to a YAML file with default and per-downstream HTTP client settings",
+		EnvVar: "HTTP_CLIENT_CONFIG",
+	})
+
+	auditSink := app.String(cli.StringOpt{
+		Name:   "audit-sink",
+		Value:  audit.SinkNone,
+		Desc:   "Where to write the suggestions audit log: none, stdout or file",
+		EnvVar: "AUDIT_SINK",
+	})
+	auditFile := app.String(cli.StringOpt{
+		Name:   "audit-file",
+		Value:  "suggestions-audit.ndjson",
+		Desc:   "The audit log file when the audit sink is file",
+		EnvVar: "AUDIT_FILE",
+	})
+	auditFileMaxSizeMB := app.Int(cli.IntOpt{
+		Name:   "audit-file-max-size-mb",
+		Value:  100,
+		Desc:   "The size in megabytes after which the audit log file is rotated",
+		EnvVar: "AUDIT_FILE_MAX_SIZE_MB",
+	})
+	auditFileMaxBackups := app.Int(cli.IntOpt{
+		Name:   "audit-file-max-backups",
+		Value:  5,
+		Desc:   "The number of rotated audit log files to keep",
+		EnvVar: "AUDIT_FILE_MAX_BACKUPS",
+	})
+	auditRedactFields := app.Strings(cli.StringsOpt{
+		Name:   "audit-redact-fields",
+		Value:  audit.DefaultRedactedFields,
+		Desc:   "Payload fields replaced by a placeholder in the audit log, nested fields are separated by dots, * drops the whole payload",
+		EnvVar: "AUDIT_REDACT_FIELDS",
+	})
+	contentTextFields := app.Strings(cli.StringsOpt{
+		Name:   "content-text-fields",
+		Value:  content.DefaultTextFields,
+		Desc:   "Payload fields at least one of which should have text, the payloads without are rejected with a 400. Empty to accept any JSON object",
+		EnvVar: "CONTENT_TEXT_FIELDS",
+	})
+	contentRequiredFields := app.Strings(cli.StringsOpt{
+		Name:   "content-required-fields",
+		Value:  []string{},
+		Desc:   "Payload fields that should not be empty, e.g. id",
+		EnvVar: "CONTENT_REQUIRED_FIELDS",
+	})
+	maxPayloadBytes := app.Int(cli.IntOpt{
+		Name:   "max-payload-bytes",
+		Value:  5 << 20,
+		Desc:   "Size of the largest payload accepted, the larger ones are rejected with a 413. 0 for no limit",
+		EnvVar: "MAX_PAYLOAD_BYTES",
+	})
+	bodyMarkup := app.String(cli.StringOpt{
+		Name:   "body-markup",
+		Value:  content.MarkupKeep,
+		Desc:   "What is done to the markup of the bodyXML and body fields before they are sent to the suggesters: keep, normalise (drop the embedded content, images, scripts and comments) or strip (keep the text only)",
+		EnvVar: "BODY_MARKUP",
+	})
+	bodyMaxLength := app.Int(cli.IntOpt{
+		Name:   "body-max-length",
+		Value:  0,
+		Desc:   "Number of characters of text the bodies are truncated to before they are sent to the suggesters. 0 for no limit",
+		EnvVar: "BODY_MAX_LENGTH",
+	})
+
+	feedbackStore := app.String(cli.StringOpt{
+		Name:   "feedback-store",
+		Value:  "",
+		Desc:   "Path to the BoltDB file storing the editorial feedback, the feedback endpoints are disabled when empty",
+		EnvVar: "FEEDBACK_STORE",
+	})
+	historyStore := app.String(cli.StringOpt{
+		Name:   "history-store",
+		Value:  "",
+		Desc:   "Path to the BoltDB file storing the suggestions returned for every content, the history endpoint is disabled when empty",
+		EnvVar: "HISTORY_STORE",
+	})
+	historyMaxVersions := app.Int(cli.IntOpt{
+		Name:   "history-max-versions",
+		Value:  20,
+		Desc:   "The number of responses kept in the history of a content",
+		EnvVar: "HISTORY_MAX_VERSIONS",
+	})
+	suppressionMode := app.String(cli.StringOpt{
+		Name:   "suppression-mode",
+		Value:  suppressionModeNone,
+		Desc:   "What to do with the concepts editors repeatedly reject: none, suppress or demote them to the end of the response. Needs a feedback store",
+		EnvVar: "SUPPRESSION_MODE",
+	})
+	suppressionRejectionRate := app.String(cli.StringOpt{
+		Name:   "suppression-rejection-rate",
+		Value:  "0.8",
+		Desc:   "The share of rejections, between 0 and 1, from which a concept is suppressed for an origin or a content type",
+		EnvVar: "SUPPRESSION_REJECTION_RATE",
+	})
+	suppressionMinDecisions := app.Int(cli.IntOpt{
+		Name:   "suppression-min-decisions",
+		Value:  5,
+		Desc:   "The number of editorial decisions needed before a concept can be suppressed",
+		EnvVar: "SUPPRESSION_MIN_DECISIONS",
+	})
+	suppressionWindow := app.String(cli.StringOpt{
+		Name:   "suppression-window",
+		Value:  "168h",
+		Desc:   "How far back the editorial decisions are counted, suppressions expire with the decisions",
+		EnvVar: "SUPPRESSION_WINDOW",
+	})
+	suppressionRefreshInterval := app.String(cli.StringOpt{
+		Name:   "suppression-refresh-interval",
+		Value:  "1m",
+		Desc:   "How often the suppressed concepts are recomputed from the feedback store",
+		EnvVar: "SUPPRESSION_REFRESH_INTERVAL",
+	})
+
+	tracingExporter := app.String(cli.StringOpt{
+		Name:   "tracing-exporter",
+		Value:  tracing.ExporterNone,
+		Desc:   "Where to export OpenTelemetry spans: none, stdout, file or otlp",
+		EnvVar: "TRACING_EXPORTER",
+	})
+	tracingOTLPEndpoint := app.String(cli.StringOpt{
+		Name:   "tracing-otlp-endpoint",
+		Value:  "",
+		Desc:   "The URL of the OTLP/HTTP collector, e.g. http://otel-collector:4318",
+		EnvVar: "TRACING_OTLP_ENDPOINT",
+	})
+	tracingFile := app.String(cli.StringOpt{
+		Name:   "tracing-file",
+		Value:  "",
+		Desc:   "The file spans are appended to when the tracing exporter is file",
+		EnvVar: "TRACING_FILE",
+	})
+	tracingSampleRatio := app.String(cli.StringOpt{
+		Name:   "tracing-sample-ratio",
+		Value:  "1",
+		Desc:   "The fraction of requests that are traced, between 0 and 1",
+		EnvVar: "TRACING_SAMPLE_RATIO",
+	})
 
 	log := logger.NewUPPLogger(*appSystemCode, *logLevel)
+
+	// newSuggester builds the aggregation of the downstream services and the reader of the stored content,
+	// instrument wraps every downstream client.
+	newSuggester := func(instrument func(downstream string, c *http.Client) *http.Client) (*service.AggregateSuggester, service.ContentReader, *web.HealthService) {
+		clientsConfig := httpclient.FileConfig{}
+		if *httpClientConfigFile != "" {
+			downstreams := []string{"authors-suggestion-api", "ontotext-suggestion-api", "public-things-api", "internal-concordances-api",
+				"concept-blacklister", "content-public-read"}
+			for _, spec := range *shadowSuggesters {
+				// the invalid specifications fail below
+				if name, _, _, err := parseShadowSuggester(spec); err == nil {
+					downstreams = append(downstreams, name)
+				}
+			}
+			var err error
+			clientsConfig, err = httpclient.LoadFile(*httpClientConfigFile, downstreams)
+			if err != nil {
+				log.WithError(err).Fatal("Could not load HTTP client configuration")
+			}
+		}
+		newClient := func(downstream, spec string) *http.Client {
+			cfg, err := clientsConfig.ConfigFor(downstream, spec)
+			if err != nil {
+				log.WithError(err).Fatal("Invalid HTTP client configuration")
+			}
+			c, err := httpclient.New(cfg)
+			if err != nil {
+				log.WithError(err).Fatalf("Could not create HTTP client for %s", downstream)
+			}
+			return instrument(downstream, c)
+		}
+
+		authorsSuggester := service.NewAuthorsSuggester(*authorsSuggestionApiBaseURL, *authorsSuggestionEndpoint, newClient("authors-suggestion-api", *authorsSuggestionHTTPClient))
+		ontotextSuggester := service.NewOntotextSuggester(*ontotextSuggestionApiBaseURL, *ontotextSuggestionEndpoint, newClient("ontotext-suggestion-api", *ontotextSuggestionHTTPClient))
+		broaderService := service.NewBroaderConceptsProvider(*publicThingsAPIBaseURL, *publicThingsEndpoint, newClient("public-things-api", *publicThingsHTTPClient))
+
+		concordanceService := service.NewConcordance(*internalConcordancesApiBaseURL, *internalConcordancesEndpoint, newClient("internal-concordances-api", *internalConcordancesHTTPClient))
+		blacklister := service.NewConceptBlacklister(*conceptBlacklisterBaseUrl, *conceptBlacklisterEndpoint, newClient("concept-blacklister", *conceptBlacklisterHTTPClient))
+		suggester := service.NewAggregateSuggester(log, concordanceService, broaderService, blacklister, authorsSuggester, ontotextSuggester)
+		suggesters := map[string]service.Suggester{
+			"authors-suggestion-api":  authorsSuggester,
+			"ontotext-suggestion-api": ontotextSuggester,
+		}
+		apis := map[string]*service.SuggestionApi{
+			"authors-suggestion-api":  &authorsSuggester.SuggestionApi,
+			"ontotext-suggestion-api": &ontotextSuggester.SuggestionApi,
+		}
+		for _, spec := range *shadowSuggesters {
+			name, baseURL, endpoint, err := parseShadowSuggester(spec)
+			if err != nil {
+				log.WithError(err).Fatal("Invalid shadow suggester")
+			}
+			shadow := service.NewShadowSuggester(name, baseURL, endpoint, newClient(name, ""))
+			suggester.Shadows = append(suggester.Shadows, shadow)
+			suggesters[name] = shadow
+			apis[name] = shadow
+		}
+		if *projectionConfigFile != "" {
+			if err := setProjections(*projectionConfigFile, apis); err != nil {
+				log.WithError(err).Fatal("Invalid payload projection configuration")
+			}
+		}
+		if *experimentConfigFile != "" {
+			experimentConfig, err := experiment.LoadFile(*experimentConfigFile)
+			if err != nil {
+				log.WithError(err).Fatal("Could not load the experiment configuration")
+			}
+			suggester.Experiment, err = experimentConfig.Build(suggesters)
+			if err != nil {
+				log.WithError(err).Fatal("Invalid experiment configuration")
+			}
+		}
+		checks := []fthealth.Check{authorsSuggester.Check(), ontotextSuggester.Check(), concordanceService.Check(), broaderService.Check(), blacklister.Check()}
+		var contentReader service.ContentReader = service.ContentDir(*contentDir)
+		if *contentDir == "" {
+			contentAPI := service.NewContentAPI(*contentAPIBaseURL, *contentAPIEndpoint, newClient("content-public-read", *contentAPIHTTPClient))
+			checks = append(checks, contentAPI.Check())
+			contentReader = contentAPI
+		}
+		healthService := web.NewHealthService(*appSystemCode, *appName, appDescription, checks...)
+		return suggester, contentReader, healthService
+	}
+
+	app.Command("replay", replayDescription, replayCommand)
+	app.Command("evaluate", evaluateDescription, func(cmd *cli.Cmd) {
+		evaluateCommand(cmd, log, func() evaluate.Suggester {
+			suggester, _, _ := newSuggester(func(_ string, c *http.Client) *http.Client { return c })
+			return suggester
+		})
+	})
+
+	app.Command("suggest", suggestDescription, func(cmd *cli.Cmd) {
+		suggestCommand(cmd, log, func() offlineSuggester {
+			suggester, _, _ := newSuggester(func(_ string, c *http.Client) *http.Client { return c })
+			return suggester
+		})
+	})
+	app.Command("fakes", fakesDescription, func(cmd *cli.Cmd) {
+		fakesCommand(cmd, log)
+	})
+	app.Command("worker", workerDescription, func(cmd *cli.Cmd) {
+		workerCommand(cmd, log, func() worker.Suggester {
+			suggester, _, _ := newSuggester(func(_ string, c *http.Client) *http.Client { return c })
+			return suggester
+		})
+	})
+
 	app.Action = func() {
 		log.Infof("App Name: %s, Port: %s", *appName, *port)
 
-		c := &http.Client{
-			Transport: &http.Transport{
-				MaxIdleConnsPerHost: 128,
-				DialContext: (&net.Dialer{
-					Timeout:   10 * time.Second,
-					KeepAlive: 30 * time.Second,
-				}).DialContext,
-			},
-			Timeout: 10 * time.Second,
+		sampleRatio, err := strconv.ParseFloat(*tracingSampleRatio, 64)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid tracing sample ratio")
+		}
+		shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+			ServiceName:  *appSystemCode,
+			Exporter:     *tracingExporter,
+			OTLPEndpoint: *tracingOTLPEndpoint,
+			FilePath:     *tracingFile,
+			SampleRatio:  sampleRatio,
+		})
+		if err != nil {
+			log.WithError(err).Fatal("Could not set up tracing")
+		}
+
+		switch *suppressionMode {
+		case suppressionModeNone:
+		case suppressionModeSuppress, suppressionModeDemote:
+			if *feedbackStore == "" {
+				log.Fatalf("The %s suppression mode needs a feedback store", *suppressionMode)
+			}
+		default:
+			log.Fatalf("Unknown suppression mode %q, expected none, suppress or demote", *suppressionMode)
 		}
 
-		authorsSuggester := service.NewAuthorsSuggester(*authorsSuggestionApiBaseURL, *authorsSuggestionEndpoint, c)
-		ontotextSuggester := service.NewOntotextSuggester(*ontotextSuggestionApiBaseURL, *ontotextSuggestionEndpoint, c)
-		broaderService := service.NewBroaderConceptsProvider(*publicThingsAPIBaseURL, *publicThingsEndpoint, c)
+		appMetrics := monitoring.New()
+		suggester, contentReader, healthService := newSuggester(func(downstream string, c *http.Client) *http.Client {
+			return tracing.InstrumentClient(appMetrics.InstrumentClient(downstream, c))
+		})
+		suggester.Observer = appMetrics
 
-		concordanceService := service.NewConcordance(*internalConcordancesApiBaseURL, *internalConcordancesEndpoint, c)
-		blacklister := service.NewConceptBlacklister(*conceptBlacklisterBaseUrl, *conceptBlacklisterEndpoint, c)
-		suggester := service.NewAggregateSuggester(log, concordanceService, broaderService, blacklister, authorsSuggester, ontotextSuggester)
-		healthService := web.NewHealthService(*appSystemCode, *appName, appDescription, authorsSuggester.Check(), ontotextSuggester.Check(), concordanceService.Check(), broaderService.Check(), blacklister.Check())
+		requestHandler := web.NewRequestHandler(suggester, log)
+		contentValidator, err := content.NewValidator(content.Rules{TextFields: *contentTextFields, RequiredFields: *contentRequiredFields})
+		if err != nil {
+			log.WithError(err).Fatal("Invalid content rules")
+		}
+		requestHandler.ContentValidator = contentValidator
+		normaliser, err := content.NewNormaliser(*bodyMarkup, *bodyMaxLength)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid body normalisation")
+		}
+		requestHandler.Normaliser = normaliser
+		requestHandler.MaxPayloadBytes = int64(*maxPayloadBytes)
+		requestHandler.ContentReader = contentReader
+		if *auditSink != audit.SinkNone {
+			sink, err := newAuditSink(*auditSink, *auditFile, *auditFileMaxSizeMB, *auditFileMaxBackups)
+			if err != nil {
+				log.WithError(err).Fatal("Could not create the audit sink")
+			}
+			requestHandler.Auditor = audit.New(sink, *auditRedactFields, log)
+			defer requestHandler.Auditor.Close()
+		}
+
+		var feedbackHandler *web.FeedbackHandler
+		if *feedbackStore != "" {
+			store, err := feedback.OpenBoltStore(*feedbackStore)
+			if err != nil {
+				log.WithError(err).Fatal("Could not open the feedback store")
+			}
+			defer store.Close()
+			feedbackHandler = web.NewFeedbackHandler(store, log)
+
+			if *suppressionMode != suppressionModeNone {
+				suppressor, interval, err := newSuppressor(store, *suppressionRejectionRate, *suppressionMinDecisions, *suppressionWindow, *suppressionRefreshInterval, log)
+				if err != nil {
+					log.WithError(err).Fatal("Invalid suppression configuration")
+				}
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go suppressor.Run(ctx, interval)
+				suggester.Suppressor = suppressor
+				suggester.DemoteSuppressed = *suppressionMode == suppressionModeDemote
+			}
+		}
+
+		var historyHandler *web.HistoryHandler
+		if *historyStore != "" {
+			store, err := history.OpenBoltStore(*historyStore, *historyMaxVersions)
+			if err != nil {
+				log.WithError(err).Fatal("Could not open the history store")
+			}
+			defer store.Close()
+			requestHandler.History = store
+			historyHandler = web.NewHistoryHandler(store, log)
+			if feedbackHandler != nil {
+				feedbackHandler.History = store
+			}
+		}
+
+		apiHandler, err := web.NewAPIHandler(apiDocument, apiVersion())
+		if err != nil {
+			log.WithError(err).Fatal("Could not load the API spec")
+		}
 
-		serveEndpoints(*port, web.NewRequestHandler(suggester, log), healthService, log)
+		serveEndpoints(*port, requestHandler, feedbackHandler, historyHandler, healthService, appMetrics.Handler(), apiHandler, log)
+		suggester.WaitForShadows()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.WithError(err).Error("Could not flush the pending spans")
+		}
 
 	}
 	err := app.Run(os.Args)
@@ -149,23 +580,91 @@ func main() {
 	}
 }
 
-func serveEndpoints(port string, handler *web.RequestHandler, healthService *web.HealthService, log *logger.UPPLogger) {
-
-	serveMux := http.NewServeMux()
+// setProjections sets the projections of the configuration file on the suggesters, looked up by name in apis.
+func setProjections(configFile string, apis map[string]*service.SuggestionApi) error {
+	config, err := projection.LoadFile(configFile)
+	if err != nil {
+		return err
+	}
+	projections, err := config.Build()
+	if err != nil {
+		return err
+	}
+	for name, p := range projections {
+		api, ok := apis[name]
+		if !ok {
+			names := make([]string, 0, len(apis))
+			for n := range apis {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("projection of unknown suggester %q, known suggesters are %s", name, strings.Join(names, ", "))
+		}
+		api.Projection = p
+	}
+	return nil
+}
 
-	serveMux.HandleFunc(web.HealthPath, fthealth.Handler(healthService))
-	serveMux.HandleFunc(status.GTGPath, status.NewGoodToGoHandler(healthService.GTG))
-	serveMux.HandleFunc(status.BuildInfoPath, status.BuildInfoHandler)
+// parseShadowSuggester splits a name=URL shadow suggester specification into the name, the base URL and the endpoint.
+func parseShadowSuggester(spec string) (name, baseURL, endpoint string, err error) {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return "", "", "", fmt.Errorf("shadow suggester %q is not name=URL", spec)
+	}
+	u, err := url.Parse(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return "", "", "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", "", "", fmt.Errorf("shadow suggester %q has no absolute URL", spec)
+	}
+	endpoint = u.RequestURI()
+	u.Path, u.RawPath, u.RawQuery = "", "", ""
+	return strings.TrimSpace(parts[0]), u.String(), endpoint, nil
+}
 
-	servicesRouter := mux.NewRouter()
-	servicesRouter.HandleFunc(suggestPath, handler.HandleSuggestion).Methods(http.MethodPost)
+// newSuppressor parses the suppression settings and loads the suppressed concepts, it returns the refresh interval.
+func newSuppressor(store feedback.Store, rejectionRate string, minDecisions int, window, refreshInterval string, log *logger.UPPLogger) (*feedback.Suppressor, time.Duration, error) {
+	rate, err := strconv.ParseFloat(rejectionRate, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("suppression rejection rate %q is not a number", rejectionRate)
+	}
+	windowDuration, err := time.ParseDuration(window)
+	if err != nil {
+		return nil, 0, fmt.Errorf("suppression window %q is not a duration", window)
+	}
+	interval, err := time.ParseDuration(refreshInterval)
+	if err != nil || interval <= 0 {
+		return nil, 0, fmt.Errorf("suppression refresh interval %q is not a positive duration", refreshInterval)
+	}
+	suppressor, err := feedback.NewSuppressor(store, feedback.SuppressionConfig{
+		Window:        windowDuration,
+		RejectionRate: rate,
+		MinDecisions:  minDecisions,
+	}, log)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := suppressor.Refresh(); err != nil {
+		return nil, 0, fmt.Errorf("could not load the suppressed concepts: %w", err)
+	}
+	log.Infof("%d concepts suppressed from the editorial feedback", suppressor.Suppressed())
+	return suppressor, interval, nil
+}
 
-	var monitoringRouter http.Handler = servicesRouter
-	monitoringRouter = httphandlers.TransactionAwareRequestLoggingHandler(log, monitoringRouter)
-	monitoringRouter = httphandlers.HTTPMetricsHandler(metrics.DefaultRegistry, monitoringRouter)
+func newAuditSink(kind, file string, maxSizeMB, maxBackups int) (audit.Sink, error) {
+	switch kind {
+	case audit.SinkStdout:
+		return audit.NewWriterSink(os.Stdout), nil
+	case audit.SinkFile:
+		return audit.NewRotatingFileSink(file, int64(maxSizeMB)*1024*1024, maxBackups)
+	}
+	return nil, fmt.Errorf("unknown audit sink %q", kind)
+}
 
-	serveMux.Handle("/", monitoringRouter)
+func serveEndpoints(port string, handler *web.RequestHandler, feedbackHandler *web.FeedbackHandler, historyHandler *web.HistoryHandler, healthService *web.HealthService, metricsHandler http.Handler, apiHandler http.Handler, log *logger.UPPLogger) {
 
+	serveMux := newServeMux(handler, feedbackHandler, historyHandler, healthService, metricsHandler, apiHandler, log)
 	server := &http.Server{Addr: ":" + port, Handler: serveMux}
 
 	wg := sync.WaitGroup{}
@@ -191,6 +690,60 @@ func serveEndpoints(port string, handler *web.RequestHandler, healthService *web
 	wg.Wait()
 }
 
+// newServeMux routes the endpoints, the feedback and history ones only when their handler is set.
+func newServeMux(handler *web.RequestHandler, feedbackHandler *web.FeedbackHandler, historyHandler *web.HistoryHandler, healthService *web.HealthService, metricsHandler http.Handler, apiHandler http.Handler, log *logger.UPPLogger) *http.ServeMux {
+	serveMux := http.NewServeMux()
+
+	for path, adminHandler := range adminHandlers(healthService, metricsHandler, apiHandler) {
+		serveMux.Handle(path, adminHandler)
+	}
+
+	servicesRouter := newServicesRouter(handler, feedbackHandler, historyHandler)
+	var monitoringRouter http.Handler = servicesRouter
+	monitoringRouter = httphandlers.TransactionAwareRequestLoggingHandler(log, monitoringRouter)
+	monitoringRouter = httphandlers.HTTPMetricsHandler(metrics.DefaultRegistry, monitoringRouter)
+	monitoringRouter = tracing.Handler(monitoringRouter, servicesRouter)
+
+	serveMux.Handle("/", monitoringRouter)
+	return serveMux
+}
+
+// adminHandlers are the handlers of the admin endpoints by path, they are not logged nor traced.
+func adminHandlers(healthService *web.HealthService, metricsHandler http.Handler, apiHandler http.Handler) map[string]http.Handler {
+	return map[string]http.Handler{
+		web.HealthPath:         http.HandlerFunc(fthealth.Handler(healthService)),
+		status.GTGPath:         http.HandlerFunc(status.NewGoodToGoHandler(healthService.GTG)),
+		status.BuildInfoPath:   http.HandlerFunc(status.BuildInfoHandler),
+		monitoring.MetricsPath: metricsHandler,
+		web.APIPath:            apiHandler,
+	}
+}
+
+func newServicesRouter(handler *web.RequestHandler, feedbackHandler *web.FeedbackHandler, historyHandler *web.HistoryHandler) *mux.Router {
+	servicesRouter := mux.NewRouter()
+	servicesRouter.HandleFunc(suggestPath, handler.HandleSuggestion).Methods(http.MethodPost)
+	if handler.ContentReader != nil {
+		servicesRouter.HandleFunc(storedContentSuggestPath, handler.HandleStoredContentSuggestion).Methods(http.MethodGet)
+	}
+	if feedbackHandler != nil {
+		servicesRouter.HandleFunc(web.FeedbackPath, feedbackHandler.HandleFeedback).Methods(http.MethodPost)
+		servicesRouter.HandleFunc(web.FeedbackRatesPath, feedbackHandler.HandleRates).Methods(http.MethodGet)
+	}
+	if historyHandler != nil {
+		servicesRouter.HandleFunc(web.HistoryPath, historyHandler.HandleHistory).Methods(http.MethodGet)
+	}
+	return servicesRouter
+}
+
+// apiVersion is the version of the build, empty when the binary was built without a semantic version.
+func apiVersion() string {
+	v := buildinfo.GetBuildInfo().Version
+	if _, err := version.NewVersion(v); err != nil {
+		return ""
+	}
+	return v
+}
+
 func waitForSignal() {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
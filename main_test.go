@@ -7,12 +7,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/feedback"
+	"github.com/Financial-Times/public-suggestions-api/history"
+	"github.com/Financial-Times/public-suggestions-api/monitoring"
 	"github.com/Financial-Times/public-suggestions-api/service"
 	"github.com/Financial-Times/public-suggestions-api/web"
 	"github.com/stretchr/testify/assert"
@@ -48,6 +52,16 @@ func TestMainApp(t *testing.T) {
 				expect.Equal("public-suggestions-api", systemCode.(string))
 			},
 		},
+		{
+			"/metrics",
+			func(resp *http.Response) {
+				defer resp.Body.Close()
+				expect.Equal(http.StatusOK, resp.StatusCode)
+				body, err := ioutil.ReadAll(resp.Body)
+				expect.NoError(err)
+				expect.Contains(string(body), "public_suggestions_api_blacklist_size")
+			},
+		},
 	}
 
 	waitCh := make(chan struct{})
@@ -254,10 +268,25 @@ func TestRequestHandler_all(t *testing.T) {
 	suggester := service.NewAggregateSuggester(log, concordance, broaderProvider, blacklister, authorsSuggester, ontotextSuggester)
 	healthService := web.NewHealthService("mock", "mock", "", authorsSuggester.Check(), ontotextSuggester.Check(), broaderProvider.Check())
 
+	feedbackStore, err := feedback.OpenBoltStore(filepath.Join(t.TempDir(), "feedback.db"))
+	require.NoError(t, err)
+	defer feedbackStore.Close()
+	historyStore, err := history.OpenBoltStore(filepath.Join(t.TempDir(), "history.db"), 5)
+	require.NoError(t, err)
+	defer historyStore.Close()
+
+	contentDir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(contentDir, "f758ef56-c40a-3162-91aa-3e8a3aabc495.json"), []byte(`{"body":"test"}`), 0644))
+	requestHandler := web.NewRequestHandler(suggester, log)
+	requestHandler.ContentReader = service.ContentDir(contentDir)
+
+	apiHandler, err := web.NewAPIHandler(apiDocument, "")
+	require.NoError(t, err)
 	go func() {
-		serveEndpoints("8081", web.NewRequestHandler(suggester, log), healthService, log)
+		serveEndpoints("8081", requestHandler, web.NewFeedbackHandler(feedbackStore, log), web.NewHistoryHandler(historyStore, log), healthService, monitoring.New().Handler(), apiHandler, log)
 	}()
 	client := &http.Client{}
+	waitForServer(t, "localhost:8081")
 
 	for _, test := range tests {
 
@@ -281,4 +310,98 @@ func TestRequestHandler_all(t *testing.T) {
 		}
 	}
 
+	res, err := client.Get("http://localhost:8081/content/f758ef56-c40a-3162-91aa-3e8a3aabc495/suggest")
+	require.NoError(t, err)
+	var stored service.SuggestionsResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&stored))
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Len(t, stored.Suggestions, len(expectedAuthorsSuggestions)+len(expectedOntotextSuggestions))
+
+	res, err = client.Get("http://localhost:8081/content/f1b2c3d4-0000-4000-8000-000000000001/suggest")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, err = client.Post("http://localhost:8081/content/f1b2c3d4-0000-4000-8000-000000000001/suggestions/feedback", "application/json",
+		strings.NewReader(`{"decisions":[{"id":"http://www.ft.com/thing/f758ef56-c40a-3162-91aa-3e8a3aabc495","type":"http://www.ft.com/ontology/Location","decision":"accepted"}]}`))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = client.Get("http://localhost:8081/suggestions/feedback/rates")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	var rates feedback.Rates
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&rates))
+	assert.Equal(t, 1, rates.ByConceptType["Location"].Accepted)
+
+	res, err = client.Get("http://localhost:8081/content/f1b2c3d4-0000-4000-8000-000000000001/suggestions/history")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode, "the suggested payloads have no id")
+}
+
+func waitForServer(t *testing.T, addr string) {
+	for i := 0; i < 50; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("server on %s did not start", addr)
+}
+
+func TestParseShadowSuggester(t *testing.T) {
+	name, baseURL, endpoint, err := parseShadowSuggester("ontotext-v2=http://ontotext-v2:8080/content/suggest/ontotext?mode=trial")
+	require.NoError(t, err)
+	assert.Equal(t, "ontotext-v2", name)
+	assert.Equal(t, "http://ontotext-v2:8080", baseURL)
+	assert.Equal(t, "/content/suggest/ontotext?mode=trial", endpoint)
+
+	for _, spec := range []string{"http://ontotext-v2:8080/suggest", "=http://ontotext-v2:8080/suggest", "ontotext-v2=/suggest"} {
+		_, _, _, err := parseShadowSuggester(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNewSuppressor(t *testing.T) {
+	store, err := feedback.OpenBoltStore(filepath.Join(t.TempDir(), "feedback.db"))
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.Add(feedback.Record{Time: time.Now(), ConceptID: "http://www.ft.com/thing/a", Origin: "spark", Decision: feedback.Rejected}))
+	log := logger.NewUPPLogger("test", "panic")
+
+	suppressor, interval, err := newSuppressor(store, "1", 1, "168h", "1m", log)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, interval)
+	assert.True(t, suppressor.IsSuppressed("a", "spark", ""), "the suppressed concepts are loaded")
+
+	for _, args := range [][]string{{"high", "168h", "1m"}, {"1.5", "168h", "1m"}, {"1", "week", "1m"}, {"1", "168h", "0s"}} {
+		_, _, err := newSuppressor(store, args[0], 1, args[1], args[2], log)
+		assert.Error(t, err, args)
+	}
+}
+
+func TestSetProjections(t *testing.T) {
+	authors := service.NewAuthorsSuggester("http://authors", "/content/suggest/authors", http.DefaultClient)
+	ontotext := service.NewOntotextSuggester("http://ontotext", "/content/suggest/ontotext", http.DefaultClient)
+	apis := map[string]*service.SuggestionApi{
+		"authors-suggestion-api":  &authors.SuggestionApi,
+		"ontotext-suggestion-api": &ontotext.SuggestionApi,
+	}
+
+	file := filepath.Join(t.TempDir(), "projections.yml")
+	require.NoError(t, ioutil.WriteFile(file, []byte("authors-suggestion-api:\n  fields: [byline, bodyXML]\n"), 0644))
+	require.NoError(t, setProjections(file, apis))
+	require.NotNil(t, authors.Projection)
+	assert.Nil(t, ontotext.Projection)
+	projected, err := authors.Projection.Project([]byte(`{"title":"title","byline":"byline"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"byline":"byline"}`, string(projected))
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("ontotext-v2:\n  fields: [title]\n"), 0644))
+	assert.EqualError(t, setProjections(file, apis), `projection of unknown suggester "ontotext-v2", known suggesters are authors-suggestion-api, ontotext-suggestion-api`)
 }
@@ -0,0 +1,147 @@
+// Package monitoring exposes the Prometheus metrics of the service: the latency and errors of every downstream
+// call and the outcome of the suggestions aggregation.
+package monitoring
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	MetricsPath = "/metrics"
+	namespace   = "public_suggestions_api"
+)
+
+// Metrics holds the collectors of the service.
+// It implements service.Observer so it can be plugged into the AggregateSuggester.
+type Metrics struct {
+	registry           *prometheus.Registry
+	downstreamDuration *prometheus.HistogramVec
+	downstreamErrors   *prometheus.CounterVec
+	suggestions        *prometheus.CounterVec
+	dropped            *prometheus.CounterVec
+	blacklistSize      prometheus.Gauge
+	shadowSuggestions  *prometheus.CounterVec
+}
+
+// New creates the service collectors and registers them, together with the Go runtime and process collectors,
+// on a dedicated registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		downstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "downstream_request_duration_seconds",
+			Help:      "Latency of the requests made to the downstream services.",
+			Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"downstream", "code"}),
+		downstreamErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "downstream_request_errors_total",
+			Help:      "Failed requests made to the downstream services, by failure reason.",
+		}, []string{"downstream", "reason"}),
+		suggestions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "suggestions_total",
+			Help:      "Suggestions returned to the clients, by source and concept type.",
+		}, []string{"source", "concept_type"}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "suggestions_dropped_total",
+			Help:      "Suggestion candidates removed before the response, by filter.",
+		}, []string{"filter"}),
+		blacklistSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "blacklist_size",
+			Help:      "Number of concepts in the last retrieved suggestions blacklist.",
+		}),
+		shadowSuggestions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "shadow_suggestions_total",
+			Help:      "Suggestions of the shadow suggesters compared with the response, by concept type and overlap: shared, shadow_only or live_only.",
+		}, []string{"shadow", "concept_type", "overlap"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.downstreamDuration,
+		m.downstreamErrors,
+		m.suggestions,
+		m.dropped,
+		m.blacklistSize,
+		m.shadowSuggestions,
+	)
+	return m
+}
+
+// Registry returns the registry the collectors are registered on, so other packages can add their own.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// InstrumentClient wraps the transport of the client to record the latency and the errors of the downstream calls.
+// The client is modified in place and returned for convenience.
+func (m *Metrics) InstrumentClient(downstream string, c *http.Client) *http.Client {
+	next := c.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c.Transport = &instrumentedTransport{
+		next:       next,
+		downstream: downstream,
+		metrics:    m,
+	}
+	return c
+}
+
+func (m *Metrics) SuggestionsReturned(source, conceptType string, count int) {
+	m.suggestions.WithLabelValues(source, conceptType).Add(float64(count))
+}
+
+func (m *Metrics) SuggestionsDropped(filter string, count int) {
+	if count > 0 {
+		m.dropped.WithLabelValues(filter).Add(float64(count))
+	}
+}
+
+func (m *Metrics) BlacklistFetched(size int) {
+	m.blacklistSize.Set(float64(size))
+}
+
+func (m *Metrics) ShadowCompared(shadow, conceptType string, shared, shadowOnly, liveOnly int) {
+	m.shadowSuggestions.WithLabelValues(shadow, conceptType, "shared").Add(float64(shared))
+	m.shadowSuggestions.WithLabelValues(shadow, conceptType, "shadow_only").Add(float64(shadowOnly))
+	m.shadowSuggestions.WithLabelValues(shadow, conceptType, "live_only").Add(float64(liveOnly))
+}
+
+type instrumentedTransport struct {
+	next       http.RoundTripper
+	downstream string
+	metrics    *Metrics
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start).Seconds()
+	if err != nil {
+		t.metrics.downstreamDuration.WithLabelValues(t.downstream, "error").Observe(elapsed)
+		t.metrics.downstreamErrors.WithLabelValues(t.downstream, "transport").Inc()
+		return resp, err
+	}
+	t.metrics.downstreamDuration.WithLabelValues(t.downstream, strconv.Itoa(resp.StatusCode)).Observe(elapsed)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		t.metrics.downstreamErrors.WithLabelValues(t.downstream, "http_5xx").Inc()
+	}
+	return resp, err
+}
@@ -0,0 +1,70 @@
+package monitoring
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_InstrumentClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := New()
+	c := m.InstrumentClient("ontotext-suggestion-api", &http.Client{})
+
+	resp, err := c.Get(server.URL + "/ok")
+	require.NoError(t, err)
+	resp.Body.Close()
+	resp, err = c.Get(server.URL + "/fail")
+	require.NoError(t, err)
+	resp.Body.Close()
+	_, err = c.Get("http://127.0.0.1:1/unreachable")
+	require.Error(t, err)
+
+	assert.Equal(t, 3, testutil.CollectAndCount(m.downstreamDuration))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.downstreamErrors.WithLabelValues("ontotext-suggestion-api", "http_5xx")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.downstreamErrors.WithLabelValues("ontotext-suggestion-api", "transport")))
+}
+
+func TestMetrics_Observer(t *testing.T) {
+	m := New()
+	m.SuggestionsReturned("Ontotext Suggestion API", "Person", 2)
+	m.SuggestionsReturned("Ontotext Suggestion API", "Person", 1)
+	m.SuggestionsDropped("blacklist", 3)
+	m.SuggestionsDropped("broader", 0)
+	m.BlacklistFetched(42)
+	m.ShadowCompared("Ontotext v2", "Person", 2, 1, 0)
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.suggestions.WithLabelValues("Ontotext Suggestion API", "Person")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.dropped.WithLabelValues("blacklist")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.dropped), "filters that dropped nothing are not reported")
+	assert.Equal(t, float64(42), testutil.ToFloat64(m.blacklistSize))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.shadowSuggestions.WithLabelValues("Ontotext v2", "Person", "shared")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.shadowSuggestions.WithLabelValues("Ontotext v2", "Person", "shadow_only")))
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.BlacklistFetched(7)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body, _ := ioutil.ReadAll(rec.Body)
+	assert.True(t, strings.Contains(string(body), "public_suggestions_api_blacklist_size 7"))
+	assert.True(t, strings.Contains(string(body), "go_goroutines"))
+}
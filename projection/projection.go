@@ -0,0 +1,167 @@
+// Package projection builds the payload every suggester receives from the content payload.
+package projection
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the projection configuration file, by suggester name, e.g.
+//
+//	authors-suggestion-api:
+//	  fields: [byline, bodyXML]
+//	ontotext-suggestion-api:
+//	  fields: [title, alternativeTitles.promotionalTitle, standfirst, bodyXML]
+//	ontotext-v2:
+//	  template: '{"text": {{json .title}}, "body": {{json .bodyXML}}}'
+//
+// The suggesters without a projection receive the whole payload.
+type Config map[string]ProjectionConfig
+
+// ProjectionConfig is either an allowlist of fields, nested fields are separated by dots, or a text/template
+// executed with the payload object that should produce a JSON object. The json function writes a value as JSON.
+type ProjectionConfig struct {
+	Fields   []string `yaml:"fields"`
+	Template string   `yaml:"template"`
+}
+
+// LoadFile reads a projection configuration file in YAML (or JSON) format.
+func LoadFile(path string) (Config, error) {
+	var c Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err = yaml.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("parsing projection configuration %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Build creates the projection of every suggester of the configuration.
+func (c Config) Build() (map[string]*Projection, error) {
+	projections := make(map[string]*Projection, len(c))
+	for name, pc := range c {
+		var p *Projection
+		var err error
+		switch {
+		case len(pc.Fields) > 0 && pc.Template != "":
+			return nil, fmt.Errorf("the projection of %s has both fields and a template", name)
+		case len(pc.Fields) > 0:
+			p, err = NewFields(pc.Fields...)
+		case pc.Template != "":
+			p, err = NewTemplate(name, pc.Template)
+		default:
+			return nil, fmt.Errorf("the projection of %s has neither fields nor a template", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("the projection of %s is invalid: %w", name, err)
+		}
+		projections[name] = p
+	}
+	return projections, nil
+}
+
+// Projection builds a payload from the fields of the content payload.
+type Projection struct {
+	fields   [][]string
+	template *template.Template
+}
+
+// NewFields keeps the fields of the payload, nested fields are separated by dots.
+func NewFields(fields ...string) (*Projection, error) {
+	p := &Projection{}
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+		for _, name := range path {
+			if name == "" {
+				return nil, fmt.Errorf("field %q has an empty name", field)
+			}
+		}
+		p.fields = append(p.fields, path)
+	}
+	return p, nil
+}
+
+// NewTemplate executes the text/template with the payload object.
+func NewTemplate(name, text string) (*Projection, error) {
+	t, err := template.New(name).Funcs(template.FuncMap{"json": toJSON}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Projection{template: t}, nil
+}
+
+// Project returns the payload built from the JSON object payload.
+func (p *Projection) Project(payload []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("projecting the payload: %w", err)
+	}
+
+	if p.template != nil {
+		var buf bytes.Buffer
+		if err := p.template.Execute(&buf, fields); err != nil {
+			return nil, fmt.Errorf("projecting the payload: %w", err)
+		}
+		var projected map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &projected); err != nil {
+			return nil, fmt.Errorf("projecting the payload: the template %s does not produce a JSON object: %w", p.template.Name(), err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	projected := map[string]interface{}{}
+	for _, path := range p.fields {
+		copyField(projected, fields, path)
+	}
+	return marshal(projected)
+}
+
+// copyField copies the value at the path of from into to, creating the objects on the way.
+func copyField(to, from map[string]interface{}, path []string) {
+	value, ok := from[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		to[path[0]] = value
+		return
+	}
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	projected, ok := to[path[0]].(map[string]interface{})
+	if !ok {
+		projected = map[string]interface{}{}
+	}
+	copyField(projected, nested, path[1:])
+	if len(projected) > 0 {
+		to[path[0]] = projected
+	}
+}
+
+func toJSON(value interface{}) (string, error) {
+	data, err := marshal(value)
+	return string(data), err
+}
+
+// marshal writes the value as JSON without escaping the markup of the bodies.
+func marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,78 @@
+package projection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPayload = `{"id":"http://www.ft.com/thing/9d5e441e","title":"Wall Street","alternativeTitles":{"promotionalTitle":"Stocks","contentPackageTitle":"Markets"},` +
+	`"byline":"Eric Platt","standfirst":"Gauge","bodyXML":"<body><p>US stocks</p></body>","wordCount":1250}`
+
+func TestProjection_ProjectFields(t *testing.T) {
+	p, err := NewFields("byline", "bodyXML", "alternativeTitles.promotionalTitle", "wordCount", "missing", "title.nested")
+	require.NoError(t, err)
+
+	projected, err := p.Project([]byte(testPayload))
+	require.NoError(t, err)
+	assert.Equal(t, `{"alternativeTitles":{"promotionalTitle":"Stocks"},"bodyXML":"<body><p>US stocks</p></body>","byline":"Eric Platt","wordCount":1250}`, string(projected))
+}
+
+func TestProjection_ProjectTemplate(t *testing.T) {
+	p, err := NewTemplate("ontotext-v2", `{"text": {{json .title}}, "body": {{json .bodyXML}}, "missing": {{json .missing}}}`)
+	require.NoError(t, err)
+
+	projected, err := p.Project([]byte(testPayload))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"text":"Wall Street","body":"<body><p>US stocks</p></body>","missing":null}`, string(projected))
+
+	p, err = NewTemplate("broken", `{"text": {{.title}}}`)
+	require.NoError(t, err)
+	_, err = p.Project([]byte(testPayload))
+	assert.EqualError(t, err, "projecting the payload: the template broken does not produce a JSON object: invalid character 'W' looking for beginning of value")
+}
+
+func TestProjection_ProjectInvalidPayload(t *testing.T) {
+	p, err := NewFields("title")
+	require.NoError(t, err)
+	_, err = p.Project([]byte(`["not","an","object"]`))
+	assert.Error(t, err)
+}
+
+func TestConfig_Build(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "projections.yml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+authors-suggestion-api:
+  fields: [byline, bodyXML]
+ontotext-v2:
+  template: '{"text": {{json .title}}}'
+`), 0644))
+	c, err := LoadFile(file)
+	require.NoError(t, err)
+	projections, err := c.Build()
+	require.NoError(t, err)
+	assert.Len(t, projections, 2)
+
+	projected, err := projections["authors-suggestion-api"].Project([]byte(testPayload))
+	require.NoError(t, err)
+	assert.Equal(t, `{"bodyXML":"<body><p>US stocks</p></body>","byline":"Eric Platt"}`, string(projected))
+}
+
+func TestConfig_BuildErrors(t *testing.T) {
+	testCases := []struct {
+		config Config
+		err    string
+	}{
+		{config: Config{"a": {}}, err: "the projection of a has neither fields nor a template"},
+		{config: Config{"a": {Fields: []string{"title"}, Template: "{}"}}, err: "the projection of a has both fields and a template"},
+		{config: Config{"a": {Fields: []string{"alternativeTitles."}}}, err: `the projection of a is invalid: field "alternativeTitles." has an empty name`},
+		{config: Config{"a": {Template: "{{.title"}}, err: "the projection of a is invalid: template: a:1: unclosed action"},
+	}
+	for _, tc := range testCases {
+		_, err := tc.config.Build()
+		assert.EqualError(t, err, tc.err)
+	}
+}
@@ -0,0 +1,141 @@
+// Package rdf writes the suggestions for a content as annotation triples, content → predicate → concept,
+// in JSON-LD or Turtle.
+package rdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Financial-Times/public-suggestions-api/service"
+)
+
+const (
+	MediaTypeJSONLD = "application/ld+json"
+	MediaTypeTurtle = "text/turtle"
+
+	// DefaultPredicate annotates the content with the suggestions the suggesters returned without a predicate.
+	DefaultPredicate = "http://www.ft.com/ontology/annotation/mentions"
+
+	thingPrefix = "http://www.ft.com/thing/"
+	skosIRI     = "http://www.w3.org/2004/02/skos/core#"
+)
+
+// Graph is the annotations of a content.
+type Graph struct {
+	// ContentID is the IRI of the content, empty when the payload has none and the content is a blank node.
+	ContentID   string
+	Suggestions []service.Suggestion
+}
+
+// NewGraph annotates the content of the payload, identified by its id or uuid field, with the suggestions.
+func NewGraph(payload []byte, suggestions []service.Suggestion) Graph {
+	g := Graph{Suggestions: suggestions}
+	if uuid := service.ContentUUID(payload); uuid != "" {
+		g.ContentID = thingPrefix + uuid
+	}
+	return g
+}
+
+// JSONLD returns the content node with the concepts of each predicate, the predicates are full IRIs.
+func (g Graph) JSONLD() ([]byte, error) {
+	node := map[string]interface{}{
+		"@context": map[string]interface{}{
+			"prefLabel": skosIRI + "prefLabel",
+		},
+	}
+	if g.ContentID != "" {
+		node["@id"] = g.ContentID
+	}
+	for _, predicate := range g.predicates() {
+		var concepts []map[string]interface{}
+		for _, s := range g.Suggestions {
+			if predicateOf(s) != predicate {
+				continue
+			}
+			concept := map[string]interface{}{"@id": s.ID}
+			if s.Type != "" {
+				concept["@type"] = s.Type
+			}
+			if s.PrefLabel != "" {
+				concept["prefLabel"] = s.PrefLabel
+			}
+			concepts = append(concepts, concept)
+		}
+		node[predicate] = concepts
+	}
+	return json.Marshal(node)
+}
+
+// Turtle returns one annotation triple per suggestion, followed by the type and prefLabel of its concept.
+func (g Graph) Turtle() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("@prefix skos: <" + skosIRI + "> .\n")
+
+	// the content is a blank node when it has no id
+	subject := "_:content"
+	if g.ContentID != "" {
+		subject = iri(g.ContentID)
+	}
+	described := map[string]bool{}
+	for _, s := range g.Suggestions {
+		fmt.Fprintf(&buf, "\n%s %s %s .\n", subject, iri(predicateOf(s)), iri(s.ID))
+		if described[s.ID] || s.Type == "" && s.PrefLabel == "" {
+			continue
+		}
+		described[s.ID] = true
+		var properties []string
+		if s.Type != "" {
+			properties = append(properties, "a "+iri(s.Type))
+		}
+		if s.PrefLabel != "" {
+			properties = append(properties, "skos:prefLabel "+literal(s.PrefLabel))
+		}
+		fmt.Fprintf(&buf, "%s %s .\n", iri(s.ID), strings.Join(properties, " ;\n    "))
+	}
+	return buf.Bytes()
+}
+
+// predicates returns the sorted predicates of the suggestions.
+func (g Graph) predicates() []string {
+	seen := map[string]bool{}
+	var predicates []string
+	for _, s := range g.Suggestions {
+		if p := predicateOf(s); !seen[p] {
+			seen[p] = true
+			predicates = append(predicates, p)
+		}
+	}
+	sort.Strings(predicates)
+	return predicates
+}
+
+func predicateOf(s service.Suggestion) string {
+	if s.Predicate == "" {
+		return DefaultPredicate
+	}
+	return s.Predicate
+}
+
+// iri writes an IRI reference, percent-encoding the characters Turtle does not allow in it.
+func iri(s string) string {
+	var b strings.Builder
+	b.WriteString("<")
+	for _, r := range s {
+		if r <= ' ' || strings.ContainsRune("<>\"{}|^`\\", r) {
+			fmt.Fprintf(&b, "%%%02X", r)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+var literalReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func literal(s string) string {
+	return `"` + literalReplacer.Replace(s) + `"`
+}
@@ -0,0 +1,57 @@
+package rdf
+
+import (
+	"testing"
+
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSuggestions = []service.Suggestion{
+	{Concept: service.Concept{ID: "http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a50", Type: "http://www.ft.com/ontology/organisation/Organisation", PrefLabel: "Apple"}},
+	{Predicate: "http://www.ft.com/ontology/annotation/hasAuthor",
+		Concept: service.Concept{ID: "http://www.ft.com/thing/f758ef56-c40a-3162-91aa-3e8a3aabc494", Type: "http://www.ft.com/ontology/person/Person", PrefLabel: `Adam "The Pen" Samson`}},
+}
+
+func TestNewGraph(t *testing.T) {
+	g := NewGraph([]byte(`{"id":"http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09"}`), testSuggestions)
+	assert.Equal(t, "http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09", g.ContentID)
+	assert.Equal(t, "http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09", NewGraph([]byte(`{"uuid":"9d5e441e-0b02-11e8-8eb7-42f857ea9f09"}`), nil).ContentID)
+	assert.Equal(t, "", NewGraph([]byte(`{"bodyXML":"text"}`), nil).ContentID)
+}
+
+func TestGraph_JSONLD(t *testing.T) {
+	jsonLD, err := Graph{ContentID: "http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09", Suggestions: testSuggestions}.JSONLD()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"@context": {"prefLabel": "http://www.w3.org/2004/02/skos/core#prefLabel"},
+		"@id": "http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09",
+		"http://www.ft.com/ontology/annotation/hasAuthor": [{"@id": "http://www.ft.com/thing/f758ef56-c40a-3162-91aa-3e8a3aabc494",
+			"@type": "http://www.ft.com/ontology/person/Person", "prefLabel": "Adam \"The Pen\" Samson"}],
+		"http://www.ft.com/ontology/annotation/mentions": [{"@id": "http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a50",
+			"@type": "http://www.ft.com/ontology/organisation/Organisation", "prefLabel": "Apple"}]
+	}`, string(jsonLD))
+
+	jsonLD, err = Graph{}.JSONLD()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"@context": {"prefLabel": "http://www.w3.org/2004/02/skos/core#prefLabel"}}`, string(jsonLD))
+}
+
+func TestGraph_Turtle(t *testing.T) {
+	turtle := Graph{ContentID: "http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09", Suggestions: testSuggestions}.Turtle()
+	assert.Equal(t, `@prefix skos: <http://www.w3.org/2004/02/skos/core#> .
+
+<http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09> <http://www.ft.com/ontology/annotation/mentions> <http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a50> .
+<http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a50> a <http://www.ft.com/ontology/organisation/Organisation> ;
+    skos:prefLabel "Apple" .
+
+<http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09> <http://www.ft.com/ontology/annotation/hasAuthor> <http://www.ft.com/thing/f758ef56-c40a-3162-91aa-3e8a3aabc494> .
+<http://www.ft.com/thing/f758ef56-c40a-3162-91aa-3e8a3aabc494> a <http://www.ft.com/ontology/person/Person> ;
+    skos:prefLabel "Adam \"The Pen\" Samson" .
+`, string(turtle))
+
+	turtle = Graph{Suggestions: []service.Suggestion{{Concept: service.Concept{ID: "http://example.com/a b"}}}}.Turtle()
+	assert.Equal(t, "@prefix skos: <http://www.w3.org/2004/02/skos/core#> .\n\n"+
+		"_:content <http://www.ft.com/ontology/annotation/mentions> <http://example.com/a%20b> .\n", string(turtle))
+}
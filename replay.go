@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Financial-Times/public-suggestions-api/replay"
+	cli "github.com/jawher/mow.cli"
+)
+
+const replayDescription = "Replay recorded content payloads against two instances, or two experiment variants, and report the suggestion differences"
+
+func replayCommand(cmd *cli.Cmd) {
+	cmd.Spec = "--baseline [--candidate] [--baseline-variant] [--candidate-variant] [--input] [--format] [--concurrency] [--timeout]"
+
+	baselineURL := cmd.String(cli.StringOpt{
+		Name: "baseline",
+		Desc: "The suggest URL of the baseline instance, e.g. http://localhost:8080/content/suggest",
+	})
+	candidateURL := cmd.String(cli.StringOpt{
+		Name: "candidate",
+		Desc: "The suggest URL of the candidate instance, e.g. http://localhost:8081/content/suggest, the baseline one by default",
+	})
+	baselineVariant := cmd.String(cli.StringOpt{
+		Name: "baseline-variant",
+		Desc: "The experiment variant of the baseline requests, the assigned one by default",
+	})
+	candidateVariant := cmd.String(cli.StringOpt{
+		Name: "candidate-variant",
+		Desc: "The experiment variant of the candidate requests, the assigned one by default",
+	})
+	input := cmd.String(cli.StringOpt{
+		Name:  "input",
+		Value: "-",
+		Desc:  "NDJSON file with one content payload, or one unredacted audit log record, per line, - for stdin",
+	})
+	format := cmd.String(cli.StringOpt{
+		Name:  "format",
+		Value: "text",
+		Desc:  "Report format: text or json",
+	})
+	concurrency := cmd.Int(cli.IntOpt{
+		Name:  "concurrency",
+		Value: 4,
+		Desc:  "Number of payloads replayed in parallel",
+	})
+	timeout := cmd.String(cli.StringOpt{
+		Name:  "timeout",
+		Value: "30s",
+		Desc:  "Timeout of each suggest request",
+	})
+
+	cmd.Action = func() {
+		baseline := &replay.HTTPTarget{URL: *baselineURL, Variant: *baselineVariant}
+		candidate := &replay.HTTPTarget{URL: *candidateURL, Variant: *candidateVariant}
+		if err := runReplay(baseline, candidate, *input, *format, *concurrency, *timeout, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "replay failed: %v\n", err)
+			cli.Exit(1)
+		}
+	}
+}
+
+// runReplay compares the baseline and candidate targets, a target without URL is the baseline instance.
+func runReplay(baseline, candidate *replay.HTTPTarget, input, format string, concurrency int, timeout string, out io.Writer) error {
+	if candidate.URL == "" {
+		candidate.URL = baseline.URL
+	}
+	if candidate.URL == baseline.URL && candidate.Variant == baseline.Variant {
+		return errors.New("the baseline and the candidate are the same, set another candidate URL or variant")
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown report format %q", format)
+	}
+	requestTimeout, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	in := io.Reader(os.Stdin)
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	client := &http.Client{Timeout: requestTimeout}
+	baseline.Client, candidate.Client = client, client
+	report, err := replay.Run(context.Background(), in, baseline, candidate, concurrency)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		return report.WriteJSON(out)
+	}
+	return report.WriteText(out)
+}
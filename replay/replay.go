@@ -0,0 +1,328 @@
+// Package replay runs recorded content payloads against two suggestion targets, e.g. the current and a candidate
+// deployment, and reports the suggestions each content item gains or loses.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Financial-Times/public-suggestions-api/audit"
+	"github.com/Financial-Times/public-suggestions-api/reqorigin"
+	"github.com/Financial-Times/public-suggestions-api/service"
+)
+
+const (
+	Origin = "public-suggestions-api-replay"
+	// VariantHeader forces the experiment variant of a suggest request.
+	VariantHeader = "X-Suggestions-Variant"
+	maxLineBytes  = 16 * 1024 * 1024
+)
+
+// Target returns the suggestions for a content payload.
+type Target interface {
+	Suggest(ctx context.Context, payload []byte, tid string) (service.SuggestionsResponse, error)
+}
+
+// HTTPTarget calls the suggest endpoint of a running public-suggestions-api instance.
+type HTTPTarget struct {
+	URL    string
+	Client service.Client
+	// Variant is the experiment variant the requests are forced into, e.g. to compare two variants of the same instance.
+	Variant string
+}
+
+func (t *HTTPTarget) Suggest(ctx context.Context, payload []byte, tid string) (service.SuggestionsResponse, error) {
+	var result service.SuggestionsResponse
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(payload))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", tid)
+	if t.Variant != "" {
+		req.Header.Set(VariantHeader, t.Variant)
+	}
+	reqorigin.SetHeader(req, Origin)
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return result, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("%s returned HTTP %d: %s", t.URL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	err = json.Unmarshal(body, &result)
+	return result, err
+}
+
+// ItemDiff holds the differences between the two targets for one content item.
+type ItemDiff struct {
+	Line           int                  `json:"line"`
+	ContentID      string               `json:"contentId,omitempty"`
+	Added          []service.Suggestion `json:"added"`
+	Removed        []service.Suggestion `json:"removed"`
+	Unchanged      int                  `json:"unchanged"`
+	BaselineError  string               `json:"baselineError,omitempty"`
+	CandidateError string               `json:"candidateError,omitempty"`
+}
+
+// Changed reports if the targets disagreed on the item.
+func (d ItemDiff) Changed() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || d.BaselineError != "" || d.CandidateError != ""
+}
+
+// TypeDiff counts the differences for one concept type over all items.
+type TypeDiff struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// Report is the result of a replay.
+type Report struct {
+	Items         []ItemDiff          `json:"items"`
+	ByConceptType map[string]TypeDiff `json:"byConceptType"`
+	Total         int                 `json:"total"`
+	Changed       int                 `json:"changed"`
+	Errors        int                 `json:"errors"`
+}
+
+// Run sends every payload of the NDJSON input to both targets, with at most concurrency payloads in flight.
+func Run(ctx context.Context, input io.Reader, baseline, candidate Target, concurrency int) (*Report, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	payloads, err := readPayloads(input)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ItemDiff, len(payloads))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i, p := range payloads {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, p payload) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			items[i] = compare(ctx, p, baseline, candidate)
+		}(i, p)
+	}
+	wg.Wait()
+
+	report := &Report{Items: items, ByConceptType: map[string]TypeDiff{}, Total: len(items)}
+	for _, item := range items {
+		if item.Changed() {
+			report.Changed++
+		}
+		if item.BaselineError != "" || item.CandidateError != "" {
+			report.Errors++
+			continue
+		}
+		for _, s := range item.Added {
+			d := report.ByConceptType[service.ConceptTypeName(s)]
+			d.Added++
+			report.ByConceptType[service.ConceptTypeName(s)] = d
+		}
+		for _, s := range item.Removed {
+			d := report.ByConceptType[service.ConceptTypeName(s)]
+			d.Removed++
+			report.ByConceptType[service.ConceptTypeName(s)] = d
+		}
+	}
+	return report, nil
+}
+
+type payload struct {
+	line      int
+	contentID string
+	body      []byte
+}
+
+// readPayloads reads the content payloads, one per line, either as they were posted or wrapped in the records
+// of an audit log.
+func readPayloads(input io.Reader) ([]payload, error) {
+	var payloads []payload
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		body := bytes.TrimSpace(scanner.Bytes())
+		if len(body) == 0 {
+			continue
+		}
+		var content map[string]json.RawMessage
+		if err := json.Unmarshal(body, &content); err != nil {
+			return nil, fmt.Errorf("line %d is not a JSON object: %w", line, err)
+		}
+		if isAuditRecord(content) {
+			var err error
+			if body, err = auditedPayload(content); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		payloads = append(payloads, payload{line: line, contentID: service.ContentUUID(body), body: append([]byte(nil), body...)})
+	}
+	return payloads, scanner.Err()
+}
+
+// isAuditRecord tells an audit.Record from a content, which has neither a transactionId nor sources.
+func isAuditRecord(content map[string]json.RawMessage) bool {
+	_, tid := content["transactionId"]
+	_, sources := content["sources"]
+	return tid && sources
+}
+
+// auditedPayload returns the payload of an audit record, which must have been recorded without redaction.
+func auditedPayload(record map[string]json.RawMessage) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(record["payload"], &fields); err != nil || fields == nil {
+		return nil, errors.New("the audit record has no payload, the audit log should be written with --audit-redact-fields ''")
+	}
+	if redacted := redactedFields(fields, ""); len(redacted) > 0 {
+		return nil, fmt.Errorf("the payload of the audit record is redacted (%s), the audit log should be written with --audit-redact-fields ''",
+			strings.Join(redacted, ", "))
+	}
+	return record["payload"], nil
+}
+
+func redactedFields(fields map[string]interface{}, prefix string) []string {
+	var redacted []string
+	for name, value := range fields {
+		switch v := value.(type) {
+		case string:
+			if v == audit.Redacted {
+				redacted = append(redacted, prefix+name)
+			}
+		case map[string]interface{}:
+			redacted = append(redacted, redactedFields(v, prefix+name+".")...)
+		}
+	}
+	sort.Strings(redacted)
+	return redacted
+}
+
+func compare(ctx context.Context, p payload, baseline, candidate Target) ItemDiff {
+	diff := ItemDiff{Line: p.line, ContentID: p.contentID, Added: []service.Suggestion{}, Removed: []service.Suggestion{}}
+	tid := fmt.Sprintf("tid_replay_%d", p.line)
+
+	var before, after service.SuggestionsResponse
+	var beforeErr, afterErr error
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		before, beforeErr = baseline.Suggest(ctx, p.body, tid)
+	}()
+	go func() {
+		defer wg.Done()
+		after, afterErr = candidate.Suggest(ctx, p.body, tid)
+	}()
+	wg.Wait()
+
+	if beforeErr != nil {
+		diff.BaselineError = beforeErr.Error()
+	}
+	if afterErr != nil {
+		diff.CandidateError = afterErr.Error()
+	}
+	if beforeErr != nil || afterErr != nil {
+		return diff
+	}
+
+	diff.Added, diff.Removed, diff.Unchanged = Diff(before.Suggestions, after.Suggestions)
+	return diff
+}
+
+// Diff compares two lists of suggestions by concept ID and predicate.
+func Diff(before, after []service.Suggestion) (added, removed []service.Suggestion, unchanged int) {
+	key := func(s service.Suggestion) string { return s.ID + " " + s.Predicate }
+	beforeKeys := map[string]bool{}
+	for _, s := range before {
+		beforeKeys[key(s)] = true
+	}
+	afterKeys := map[string]bool{}
+	added = []service.Suggestion{}
+	for _, s := range after {
+		afterKeys[key(s)] = true
+		if !beforeKeys[key(s)] {
+			added = append(added, s)
+		}
+	}
+	removed = []service.Suggestion{}
+	for _, s := range before {
+		if afterKeys[key(s)] {
+			unchanged++
+		} else {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed, unchanged
+}
+
+// WriteText writes a human readable report listing only the items that changed.
+func (r *Report) WriteText(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "Replayed %d content items: %d changed, %d with errors\n", r.Total, r.Changed, r.Errors)
+
+	types := make([]string, 0, len(r.ByConceptType))
+	for t := range r.ByConceptType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	if len(types) > 0 {
+		fmt.Fprintln(b, "\nBy concept type:")
+		for _, t := range types {
+			d := r.ByConceptType[t]
+			fmt.Fprintf(b, "  %-20s +%d -%d\n", t, d.Added, d.Removed)
+		}
+	}
+
+	for _, item := range r.Items {
+		if !item.Changed() {
+			continue
+		}
+		id := item.ContentID
+		if id == "" {
+			id = "(no id)"
+		}
+		fmt.Fprintf(b, "\nline %d %s\n", item.Line, id)
+		if item.BaselineError != "" {
+			fmt.Fprintf(b, "  baseline error: %s\n", item.BaselineError)
+		}
+		if item.CandidateError != "" {
+			fmt.Fprintf(b, "  candidate error: %s\n", item.CandidateError)
+		}
+		for _, s := range item.Added {
+			fmt.Fprintf(b, "  + %s %q (%s)\n", s.ID, s.PrefLabel, service.ConceptTypeName(s))
+		}
+		for _, s := range item.Removed {
+			fmt.Fprintf(b, "  - %s %q (%s)\n", s.ID, s.PrefLabel, service.ConceptTypeName(s))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the full report as JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,163 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/public-suggestions-api/reqorigin"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	london = service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/london", PrefLabel: "London", Type: "http://www.ft.com/ontology/Location"}}
+	apple  = service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/apple", PrefLabel: "Apple", Type: "http://www.ft.com/ontology/organisation/Organisation"}}
+	author = service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/author", PrefLabel: "Author", Type: "http://www.ft.com/ontology/person/Person"}, Predicate: "http://www.ft.com/ontology/annotation/hasAuthor"}
+)
+
+type staticTarget map[string]service.SuggestionsResponse
+
+func (t staticTarget) Suggest(_ context.Context, payload []byte, _ string) (service.SuggestionsResponse, error) {
+	resp, ok := t[service.ContentUUID(payload)]
+	if !ok {
+		return resp, errors.New("no suggestions")
+	}
+	return resp, nil
+}
+
+func TestRun(t *testing.T) {
+	input := strings.NewReader(`{"id":"http://www.ft.com/thing/a","bodyXML":"first"}
+
+{"uuid":"b","bodyXML":"second"}
+{"id":"c","bodyXML":"third"}
+`)
+	baseline := staticTarget{
+		"a": {Suggestions: []service.Suggestion{london, author}},
+		"b": {Suggestions: []service.Suggestion{london}},
+		"c": {Suggestions: []service.Suggestion{}},
+	}
+	candidate := staticTarget{
+		"a": {Suggestions: []service.Suggestion{author, apple}},
+		"b": {Suggestions: []service.Suggestion{london}},
+	}
+
+	report, err := Run(context.Background(), input, baseline, candidate, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 2, report.Changed)
+	assert.Equal(t, 1, report.Errors)
+	require.Len(t, report.Items, 3)
+
+	assert.Equal(t, ItemDiff{Line: 1, ContentID: "a", Added: []service.Suggestion{apple}, Removed: []service.Suggestion{london}, Unchanged: 1}, report.Items[0])
+	assert.Equal(t, ItemDiff{Line: 3, ContentID: "b", Added: []service.Suggestion{}, Removed: []service.Suggestion{}, Unchanged: 1}, report.Items[1])
+	assert.Equal(t, "no suggestions", report.Items[2].CandidateError)
+	assert.Equal(t, map[string]TypeDiff{"Organisation": {Added: 1}, "Location": {Removed: 1}}, report.ByConceptType)
+
+	text := &bytes.Buffer{}
+	require.NoError(t, report.WriteText(text))
+	assert.Contains(t, text.String(), "Replayed 3 content items: 2 changed, 1 with errors")
+	assert.Contains(t, text.String(), `+ http://www.ft.com/thing/apple "Apple" (Organisation)`)
+	assert.Contains(t, text.String(), `- http://www.ft.com/thing/london "London" (Location)`)
+	assert.NotContains(t, text.String(), "line 3 b", "unchanged items are not listed")
+
+	js := &bytes.Buffer{}
+	require.NoError(t, report.WriteJSON(js))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(js.Bytes(), &decoded))
+	assert.Equal(t, *report, decoded)
+}
+
+func TestRun_InvalidInput(t *testing.T) {
+	_, err := Run(context.Background(), strings.NewReader("{\"id\":\"a\"}\nnot json\n"), staticTarget{}, staticTarget{}, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestRun_AuditRecords(t *testing.T) {
+	input := strings.NewReader(`{"time":"2026-10-01T12:00:00Z","transactionId":"tid_1","contentId":"http://www.ft.com/thing/a","payload":{"id":"http://www.ft.com/thing/a","bodyXML":"first"},"sources":[],"dropped":[]}
+{"uuid":"b","bodyXML":"second"}
+`)
+	targets := staticTarget{"a": {Suggestions: []service.Suggestion{london}}, "b": {Suggestions: []service.Suggestion{london}}}
+
+	report, err := Run(context.Background(), input, targets, targets, 1)
+	require.NoError(t, err)
+	require.Len(t, report.Items, 2)
+	assert.Equal(t, ItemDiff{Line: 1, ContentID: "a", Added: []service.Suggestion{}, Removed: []service.Suggestion{}, Unchanged: 1}, report.Items[0],
+		"the payload of the audit record is replayed")
+	assert.Equal(t, 0, report.Errors)
+
+	testCases := []struct {
+		name   string
+		record string
+		err    string
+	}{
+		{
+			name:   "redacted",
+			record: `{"transactionId":"tid_1","payload":{"id":"a","bodyXML":"[REDACTED]","alternativeTitles":{"promotionalTitle":"[REDACTED]"}},"sources":[]}`,
+			err:    "line 1: the payload of the audit record is redacted (alternativeTitles.promotionalTitle, bodyXML), the audit log should be written with --audit-redact-fields ''",
+		},
+		{
+			name:   "without payload",
+			record: `{"transactionId":"tid_1","sources":[]}`,
+			err:    "line 1: the audit record has no payload, the audit log should be written with --audit-redact-fields ''",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Run(context.Background(), strings.NewReader(tc.record), targets, targets, 1)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestDiff_PredicateMatters(t *testing.T) {
+	mention := author
+	mention.Predicate = "http://www.ft.com/ontology/annotation/mentions"
+	added, removed, unchanged := Diff([]service.Suggestion{author}, []service.Suggestion{mention})
+	assert.Equal(t, []service.Suggestion{mention}, added)
+	assert.Equal(t, []service.Suggestion{author}, removed)
+	assert.Equal(t, 0, unchanged)
+}
+
+func TestHTTPTarget_Suggest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tid_test", r.Header.Get("X-Request-Id"))
+		assert.Equal(t, Origin, reqorigin.FromRequest(r))
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) == `{"fail":true}` {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message": "aggregating suggestions failed!"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(service.SuggestionsResponse{Suggestions: []service.Suggestion{london}})
+	}))
+	defer server.Close()
+
+	target := &HTTPTarget{URL: server.URL + "/content/suggest", Client: server.Client()}
+	resp, err := target.Suggest(context.Background(), []byte(`{"id":"a"}`), "tid_test")
+	require.NoError(t, err)
+	assert.Equal(t, []service.Suggestion{london}, resp.Suggestions)
+
+	variants := []string{}
+	variantServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		variants = append(variants, r.Header.Get(VariantHeader))
+		_ = json.NewEncoder(w).Encode(service.SuggestionsResponse{})
+	}))
+	defer variantServer.Close()
+	_, err = (&HTTPTarget{URL: variantServer.URL, Client: variantServer.Client(), Variant: "treatment"}).Suggest(context.Background(), []byte(`{"id":"a"}`), "tid_test")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"treatment"}, variants)
+
+	_, err = target.Suggest(context.Background(), []byte(`{"fail":true}`), "tid_test")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "returned HTTP 503")
+}
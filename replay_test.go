@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Financial-Times/public-suggestions-api/replay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunReplay(t *testing.T) {
+	suggestServer := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}))
+	}
+	baseline := suggestServer(`{"suggestions":[{"id":"http://www.ft.com/thing/1","predicate":"http://www.ft.com/ontology/annotation/mentions","type":"http://www.ft.com/ontology/person/Person","prefLabel":"Alice"}]}`)
+	defer baseline.Close()
+	candidate := suggestServer(`{"suggestions":[]}`)
+	defer candidate.Close()
+
+	input := filepath.Join(t.TempDir(), "payloads.ndjson")
+	require.NoError(t, ioutil.WriteFile(input, []byte(`{"id":"content-1","byline":"Alice"}`+"\n"), 0644))
+
+	out := &bytes.Buffer{}
+	err := runReplay(&replay.HTTPTarget{URL: baseline.URL}, &replay.HTTPTarget{URL: candidate.URL}, input, "text", 2, "5s", out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Replayed 1 content items: 1 changed, 0 with errors")
+	assert.Contains(t, out.String(), `- http://www.ft.com/thing/1 "Alice" (Person)`)
+}
+
+func TestRunReplay_Variants(t *testing.T) {
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("X-Suggestions-Variant") == "treatment" {
+			w.Write([]byte(`{"suggestions":[{"id":"http://www.ft.com/thing/1","type":"http://www.ft.com/ontology/person/Person","prefLabel":"Alice"}]}`))
+			return
+		}
+		w.Write([]byte(`{"suggestions":[]}`))
+	}))
+	defer instance.Close()
+
+	input := filepath.Join(t.TempDir(), "audit.ndjson")
+	require.NoError(t, ioutil.WriteFile(input, []byte(`{"transactionId":"tid_1","payload":{"id":"content-1","byline":"Alice"},"sources":[],"dropped":[]}`+"\n"), 0644))
+
+	out := &bytes.Buffer{}
+	err := runReplay(&replay.HTTPTarget{URL: instance.URL, Variant: "control"}, &replay.HTTPTarget{Variant: "treatment"}, input, "text", 1, "5s", out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Replayed 1 content items: 1 changed, 0 with errors")
+	assert.Contains(t, out.String(), `+ http://www.ft.com/thing/1 "Alice" (Person)`)
+}
+
+func TestRunReplay_InvalidOptions(t *testing.T) {
+	target := func(url string) *replay.HTTPTarget { return &replay.HTTPTarget{URL: url} }
+	assert.EqualError(t, runReplay(target("http://a"), target("http://b"), "-", "xml", 1, "5s", ioutil.Discard), `unknown report format "xml"`)
+	assert.Error(t, runReplay(target("http://a"), target("http://b"), "-", "text", 1, "soon", ioutil.Discard))
+	assert.EqualError(t, runReplay(target("http://a"), target(""), "-", "text", 1, "5s", ioutil.Discard),
+		"the baseline and the candidate are the same, set another candidate URL or variant")
+	err := runReplay(target("http://a"), target("http://b"), filepath.Join(t.TempDir(), "missing"), "text", 1, "5s", ioutil.Discard)
+	assert.True(t, os.IsNotExist(err))
+}
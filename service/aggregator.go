@@ -1,11 +1,14 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	fp "path/filepath"
 	"sync"
 
 	"github.com/Financial-Times/go-logger/v2"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const PanicGuideURL = "https://runbooks.in.ft.com/"
@@ -16,6 +19,18 @@ type AggregateSuggester struct {
 	Blacklister     ConceptBlacklister
 	Suggesters      []Suggester
 	Log             *logger.UPPLogger
+	// Observer is optional, it is notified about the suggestions returned and dropped by every request.
+	Observer Observer
+	// Shadows are optional Suggesters called like the Suggesters, whose filtered suggestions are only
+	// compared with the response and never returned.
+	Shadows []Suggester
+	// Experiment is optional, it assigns every request to a variant that can change the Suggesters and the filters.
+	Experiment *Experiment
+	// Suppressor is optional, the concepts it suppresses for the origin and content type of a request are removed,
+	// or moved to the end of the response when DemoteSuppressed is set.
+	Suppressor       Suppressor
+	DemoteSuppressed bool
+	shadows          sync.WaitGroup
 }
 
 func NewAggregateSuggester(log *logger.UPPLogger, concordance *ConcordanceService, broaderConceptsProvider *BroaderConceptsProvider, blacklister ConceptBlacklister, suggesters ...Suggester) *AggregateSuggester {
@@ -31,13 +46,38 @@ func NewAggregateSuggester(log *logger.UPPLogger, concordance *ConcordanceServic
 // GetSuggestions calls several services to build it's return value.
 //
 // It calls concurrently the Suggesters and the Blacklister and waits them.
-// It then calls the BroaderProvider to exclude the broader concepts.
+// It then calls the BroaderProvider to exclude the broader concepts and filters the blacklisted and suppressed ones.
+// The Shadows are started with the Suggesters but the response does not wait for them.
+// When an Experiment is set, the Suggesters and the filters are the ones of the request variant.
 //
+// ctx carries the trace of the request, every downstream call is recorded as a child span.
 // payload is the content send to the Suggesters.
 // tid is propaged down the request chain.
 // origin is propaged down only to the Suggesters.
-func (s *AggregateSuggester) GetSuggestions(payload []byte, tid, origin string) (SuggestionsResponse, error) {
+func (s *AggregateSuggester) GetSuggestions(ctx context.Context, payload []byte, tid, origin string) (SuggestionsResponse, error) {
+	ctx, span := tracer().Start(ctx, "AggregateSuggester.GetSuggestions")
+	defer span.End()
+
 	logEntry := s.Log.WithTransactionID(tid)
+	observer := s.observer()
+	report := reportFromContext(ctx)
+	timings := timingsFromContext(ctx)
+	defer timings.start(StageTotal, "Total aggregation")()
+
+	content := parseContent(payload)
+	suggesters := s.Suggesters
+	variant, err := s.assignVariant(ctx, content, tid)
+	if err != nil {
+		recordSpanError(span, err)
+		return SuggestionsResponse{Suggestions: make([]Suggestion, 0)}, err
+	}
+	if variant != nil {
+		span.SetAttributes(attribute.String("experiment.name", s.Experiment.Name), attribute.String("experiment.variant", variant.Name))
+		report.setVariant(s.Experiment.Name, variant.Name)
+		if variant.Suggesters != nil {
+			suggesters = variant.Suggesters
+		}
+	}
 
 	var aggregateResp = SuggestionsResponse{Suggestions: make([]Suggestion, 0)}
 	var responseMap = map[int][]Suggestion{}
@@ -50,11 +90,13 @@ func (s *AggregateSuggester) GetSuggestions(payload []byte, tid, origin string)
 	var mutex = sync.Mutex{}
 	var wg = sync.WaitGroup{}
 
-	for key, suggesterDelegate := range s.Suggesters {
+	shadows := s.startShadows(ctx, tid, origin, content, variant, payload)
+
+	for key, suggesterDelegate := range suggesters {
 		wg.Add(1)
 		go func(i int, delegate Suggester) {
 			defer wg.Done()
-			result, err := getSuggestions(delegate, s.Concordance, tid, origin, payload)
+			result, err := getSuggestions(ctx, i, delegate, s.Concordance, observer, tid, origin, payload)
 
 			mutex.Lock()
 			defer mutex.Unlock()
@@ -68,16 +110,27 @@ func (s *AggregateSuggester) GetSuggestions(payload []byte, tid, origin string)
 	}
 
 	var blacklist Blacklist
-	var err error
 	wg.Add(1)
 	go func(b Blacklist) {
 		defer wg.Done()
-		blacklist, err = s.Blacklister.GetBlacklist(tid)
+		defer timings.start(StageBlacklist, "Blacklist fetch")()
+		blacklist, err = s.Blacklister.GetBlacklist(ctx, tid)
 		if err != nil {
 			logEntry.WithError(err).Errorf("Error retrieving concept blacklist, filtering disabled")
+			return
 		}
+		observer.BlacklistFetched(len(blacklist.UUIDS))
 	}(blacklist)
 
+	var existing *existingAnnotations
+	if len(content.ExistingAnnotations) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			existing = s.lookupExistingAnnotations(ctx, content.ExistingAnnotations, tid)
+		}()
+	}
+
 	wg.Wait()
 
 	var nonSuggestErr error
@@ -96,24 +149,104 @@ func (s *AggregateSuggester) GetSuggestions(payload []byte, tid, origin string)
 		}
 	}
 	if nonSuggestErr != nil {
+		shadows.discard()
+		recordSpanError(span, nonSuggestErr)
 		return aggregateResp, nonSuggestErr
 	}
 
-	results, err := s.BroaderProvider.excludeBroaderConceptsFromResponse(responseMap, tid)
-	if err != nil {
-		logEntry.WithError(err).Warn("Couldn't exclude broader concepts. Response might contain broader concepts as well")
-	} else {
-		responseMap = results
+	if !variant.skips(FilterBroader) {
+		endBroader := timings.start(StageBroader, "Broader concepts exclusion")
+		results, err := s.BroaderProvider.excludeBroaderConceptsFromResponse(ctx, responseMap, tid)
+		endBroader()
+		if err != nil {
+			logEntry.WithError(err).Warn("Couldn't exclude broader concepts. Response might contain broader concepts as well")
+		} else {
+			for i, delegate := range suggesters {
+				dropped(observer, report, delegate.GetName(), FilterBroader, difference(responseMap[i], results[i]))
+			}
+			responseMap = results
+		}
 	}
 
 	// preserve results order
-	for i := range s.Suggesters {
-		filteredSuggestions := filterDisallowedSuggestions(responseMap[i], blacklist, s.Blacklister)
+	var demoted []Suggestion
+	for i, delegate := range suggesters {
+		filteredSuggestions := responseMap[i]
+		if !variant.skips(FilterBlacklist) {
+			filteredSuggestions = filterDisallowedSuggestions(responseMap[i], blacklist, s.Blacklister)
+			dropped(observer, report, delegate.GetName(), FilterBlacklist, difference(responseMap[i], filteredSuggestions))
+		}
+		returned := filteredSuggestions
+		if s.Suppressor != nil && !variant.skips(FilterSuppressed) {
+			var suppressed []Suggestion
+			filteredSuggestions, suppressed = splitSuppressed(s.Suppressor, filteredSuggestions, origin, content.contentType())
+			if s.DemoteSuppressed {
+				demoted = append(demoted, suppressed...)
+			} else {
+				dropped(observer, report, delegate.GetName(), FilterSuppressed, suppressed)
+				returned = filteredSuggestions
+			}
+		}
+		observeReturned(observer, delegate.GetName(), returned)
+		report.addReturned(i, returned)
 		aggregateResp.Suggestions = append(aggregateResp.Suggestions, filteredSuggestions...)
 	}
+	aggregateResp.Suggestions = append(aggregateResp.Suggestions, demoted...)
+	if existing != nil {
+		existing.classify(aggregateResp.Suggestions)
+		aggregateResp.ExistingAnnotations = existing.flag(blacklist, s.Blacklister)
+	}
+	shadows.compare(aggregateResp.Suggestions, blacklist)
 	return aggregateResp, nil
 }
 
+// WaitForShadows blocks until the shadow Suggesters of all the requests so far are compared, e.g. before shutting down.
+func (s *AggregateSuggester) WaitForShadows() {
+	s.shadows.Wait()
+}
+
+// assignVariant returns the Experiment variant of the request, the one of the context or else sticky by content UUID,
+// or nil without Experiment.
+func (s *AggregateSuggester) assignVariant(ctx context.Context, content payloadContent, tid string) (*Variant, error) {
+	if name := variantFromContext(ctx); name != "" {
+		if s.Experiment == nil || s.Experiment.Variant(name) == nil {
+			return nil, fmt.Errorf("%w %q", UnknownVariantError, name)
+		}
+		return s.Experiment.Variant(name), nil
+	}
+	if s.Experiment == nil {
+		return nil, nil
+	}
+	key := content.contentUUID()
+	if key == "" {
+		key = tid
+	}
+	return s.Experiment.Assign(key), nil
+}
+
+func (s *AggregateSuggester) observer() Observer {
+	if s.Observer == nil {
+		return noopObserver{}
+	}
+	return s.Observer
+}
+
+func observeReturned(observer Observer, source string, suggestions []Suggestion) {
+	counts := map[string]int{}
+	for _, suggestion := range suggestions {
+		counts[ConceptTypeName(suggestion)]++
+	}
+	for conceptType, count := range counts {
+		observer.SuggestionsReturned(source, conceptType, count)
+	}
+}
+
+// dropped notifies the Observer and the request Report about the candidates removed by a filter.
+func dropped(observer Observer, report *Report, source, filter string, suggestions []Suggestion) {
+	observer.SuggestionsDropped(filter, len(suggestions))
+	report.addDropped(source, filter, suggestions)
+}
+
 func filterDisallowedSuggestions(suggestions []Suggestion, list Blacklist, blacklister ConceptBlacklister) []Suggestion {
 	result := []Suggestion{}
 	for _, s := range suggestions {
@@ -129,23 +262,36 @@ func filterDisallowedSuggestions(suggestions []Suggestion, list Blacklist, black
 // It enriches the suggestions with concept data gathered from the ConcordanceService.
 // If the delegate fails to provide suggestions, this function returns suggesterErr error that wraps the delegate error
 // This is done in order to distinguish between errors coming from the Suggester and the ones from ConcordanceService
-func getSuggestions(delegate Suggester, concordance *ConcordanceService, tid, origin string, payload []byte) ([]Suggestion, error) {
-	resp, err := delegate.GetSuggestions(payload, tid, origin)
+func getSuggestions(ctx context.Context, index int, delegate Suggester, concordance *ConcordanceService, observer Observer, tid, origin string, payload []byte) ([]Suggestion, error) {
+	timings := timingsFromContext(ctx)
+	report := reportFromContext(ctx)
+	name := delegate.GetName()
+
+	endSuggest := timings.start(stageSuggest+stageID(name), name)
+	resp, err := delegate.GetSuggestions(ctx, payload, tid, origin)
+	endSuggest()
+	report.addSource(index, name, resp.Suggestions, err)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := enrichSuggestionsWithConceptData(concordance, tid, resp.Suggestions)
+	endConcordance := timings.start(stageConcordance+stageID(name), "Concordance of "+name)
+	enriched, unconcorded, err := enrichSuggestionsWithConceptData(ctx, concordance, tid, resp.Suggestions)
+	endConcordance()
 	if err != nil {
 		return nil, err
 	}
-	result = delegate.FilterSuggestions(result)
+	dropped(observer, report, name, FilterUnconcorded, unconcorded)
+
+	result := delegate.FilterSuggestions(enriched)
+	dropped(observer, report, name, FilterConceptType, difference(enriched, result))
 
 	return result, nil
 }
 
 // enrichSuggestionsWithConceptData uses ConcordanceService to gather more information for the suggested concepts.
-func enrichSuggestionsWithConceptData(concordance *ConcordanceService, tid string, suggestions []Suggestion) ([]Suggestion, error) {
+// The suggestions that are not known to the ConcordanceService are returned separately as unconcorded.
+func enrichSuggestionsWithConceptData(ctx context.Context, concordance *ConcordanceService, tid string, suggestions []Suggestion) (enriched []Suggestion, unconcorded []Suggestion, err error) {
 	ids := []string{}
 	for _, suggestion := range suggestions {
 		ids = append(ids, fp.Base(suggestion.Concept.ID))
@@ -153,26 +299,27 @@ func enrichSuggestionsWithConceptData(concordance *ConcordanceService, tid strin
 
 	ids = dedup(ids)
 	if len(ids) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
-	concorded, err := concordance.getConcordances(ids, tid)
+	concorded, err := concordance.getConcordances(ctx, ids, tid)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	filtered := []Suggestion{}
+	enriched = []Suggestion{}
 	for _, suggestion := range suggestions {
 		id := fp.Base(suggestion.Concept.ID)
 		c, ok := concorded.Concepts[id]
 		if !ok {
+			unconcorded = append(unconcorded, suggestion)
 			continue
 		}
-		filtered = append(filtered, Suggestion{
+		enriched = append(enriched, Suggestion{
 			Predicate: suggestion.Predicate,
 			Concept:   c,
 		})
 	}
-	return filtered, nil
+	return enriched, unconcorded, nil
 }
 
 func dedup(s []string) []string {
@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -16,6 +17,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func newInternalConcordansesMock(t *testing.T, tid string, concepts map[string]Concept) Client {
@@ -151,7 +155,7 @@ func TestAggregateSuggester_GetAuthorSuggestionsSuccessfully(t *testing.T) {
 
 	aggregateSuggester := NewAggregateSuggester(log, mockConcordance, broaderProvider, blacklister, ontotextSuggester, authorsSuggester)
 
-	response, err := aggregateSuggester.GetSuggestions([]byte{}, "tid_test", "tests_origin")
+	response, err := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
 
 	expect.NoError(err)
 	expect.Len(response.Suggestions, 2)
@@ -187,7 +191,7 @@ func TestAggregateSuggester_GetSuggestionsSuccessfullyResponseFiltered(t *testin
 	defer server.Close()
 
 	suggester := NewOntotextSuggester(server.URL, "/content/suggest", http.DefaultClient)
-	suggestionResp, err := suggester.GetSuggestions(body, "tid_test", "tests_origin")
+	suggestionResp, err := suggester.GetSuggestions(context.Background(), body, "tid_test", "tests_origin")
 	suggestionResp.Suggestions = suggester.FilterSuggestions(suggestionResp.Suggestions)
 
 	actualSuggestions := suggestionResp.Suggestions
@@ -263,7 +267,7 @@ func TestAggregateSuggester_InternalConcordancesUnavailable(t *testing.T) {
 	blacklister := NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
 
 	aggregateSuggester := NewAggregateSuggester(log, mockConcordance, broaderProvider, blacklister, suggestionAPI, suggestionAPI)
-	response, err := aggregateSuggester.GetSuggestions([]byte{}, "tid_test", "tests_origin")
+	response, err := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
 
 	expect.Error(err)
 	expect.Len(response.Suggestions, 0)
@@ -332,7 +336,7 @@ func TestAggregateSuggester_InternalConcordancesUnexpectedStatus(t *testing.T) {
 		Body:       ioutil.NopCloser(strings.NewReader("")),
 		StatusCode: http.StatusServiceUnavailable,
 	}, nil).Twice()
-	response, err := aggregateSuggester.GetSuggestions([]byte{}, "tid_test", "tests_origin")
+	response, err := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
 	expect.Error(err)
 	expect.Equal("non 200 status code returned: 503", err.Error())
 	expect.Len(response.Suggestions, 0)
@@ -342,7 +346,7 @@ func TestAggregateSuggester_InternalConcordancesUnexpectedStatus(t *testing.T) {
 		Body:       ioutil.NopCloser(strings.NewReader("")),
 		StatusCode: http.StatusBadRequest,
 	}, nil).Twice()
-	response, err = aggregateSuggester.GetSuggestions([]byte{}, "tid_test", "tests_origin")
+	response, err = aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
 	expect.Error(err)
 	expect.Equal("non 200 status code returned: 400", err.Error())
 	expect.Len(response.Suggestions, 0)
@@ -413,7 +417,7 @@ func TestAggregateSuggester_GetSuggestionsSuccessfully(t *testing.T) {
 	blacklister := NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
 
 	aggregateSuggester := NewAggregateSuggester(log, mockConcordance, broaderProvider, blacklister, suggestionApi, suggestionApi)
-	response, _ := aggregateSuggester.GetSuggestions([]byte{}, "tid_test", "tests_origin")
+	response, _ := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
 
 	expect.Len(response.Suggestions, 2)
 
@@ -483,7 +487,7 @@ func TestAggregateSuggester_GetPersonSuggestionsSuccessfully(t *testing.T) {
 	blacklister := NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
 
 	aggregateSuggester := NewAggregateSuggester(log, mockConcordance, broaderProvider, blacklister, suggestionApi, suggestionApi)
-	response, err := aggregateSuggester.GetSuggestions([]byte{}, "tid_test", "tests_origin")
+	response, err := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
 
 	expect.NoError(err)
 	expect.Len(response.Suggestions, 2)
@@ -518,7 +522,7 @@ func TestAggregateSuggester_GetEmptySuggestionsArrayIfNoAggregatedSuggestionAvai
 	blacklister := NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
 
 	aggregateSuggester := NewAggregateSuggester(log, mockConcordance, broaderProvider, blacklister, suggestionApi, suggestionApi)
-	response, err := aggregateSuggester.GetSuggestions([]byte{}, "tid_test", "tests_origin")
+	response, err := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
 
 	expect.NoError(err)
 	expect.Len(response.Suggestions, 0)
@@ -583,7 +587,7 @@ func TestAggregateSuggester_GetSuggestionsNoErrorForOntotextSuggestionApi(t *tes
 	blacklister := NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
 
 	aggregateSuggester := NewAggregateSuggester(log, mockConcordance, broaderProvider, blacklister, suggestionApi, suggestionApi)
-	response, err := aggregateSuggester.GetSuggestions([]byte{}, "tid_test", "tests_origin")
+	response, err := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
 
 	expect.NoError(err)
 	expect.Len(response.Suggestions, 1)
@@ -655,7 +659,7 @@ func TestAggregateSuggester_GetSuggestionsWithBlacklist(t *testing.T) {
 	blacklister := NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
 
 	aggregateSuggester := NewAggregateSuggester(log, mockConcordance, broaderProvider, blacklister, suggestionApi, suggestionApi)
-	response, _ := aggregateSuggester.GetSuggestions([]byte{}, "tid_test", "tests_origin")
+	response, _ := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
 
 	expect.Len(response.Suggestions, 1)
 
@@ -726,7 +730,7 @@ func TestAggregateSuggester_GetSuggestionsWithBlacklistError(t *testing.T) {
 	blacklister := NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
 
 	aggregateSuggester := NewAggregateSuggester(log, mockConcordance, broaderProvider, blacklister, suggestionApi, suggestionApi)
-	response, _ := aggregateSuggester.GetSuggestions([]byte{}, "tid_test", "tests_origin")
+	response, _ := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
 
 	expect.Len(response.Suggestions, 2)
 
@@ -735,3 +739,134 @@ func TestAggregateSuggester_GetSuggestionsWithBlacklistError(t *testing.T) {
 
 	suggestionApi.AssertExpectations(t)
 }
+
+type recordingObserver struct {
+	returned      map[string]int
+	dropped       map[string]int
+	blacklistSize int
+	shadows       []string
+}
+
+func (o *recordingObserver) SuggestionsReturned(source, conceptType string, count int) {
+	o.returned[source+"/"+conceptType] += count
+}
+
+func (o *recordingObserver) SuggestionsDropped(filter string, count int) {
+	o.dropped[filter] += count
+}
+
+func (o *recordingObserver) BlacklistFetched(size int) {
+	o.blacklistSize = size
+}
+
+func (o *recordingObserver) ShadowCompared(shadow, conceptType string, shared, shadowOnly, liveOnly int) {
+	o.shadows = append(o.shadows, fmt.Sprintf("%s/%s shared=%d shadowOnly=%d liveOnly=%d", shadow, conceptType, shared, shadowOnly, liveOnly))
+}
+
+func TestAggregateSuggester_GetSuggestionsNotifiesObserver(t *testing.T) {
+	expect := assert.New(t)
+
+	suggestionApi := new(mockSuggestionApi)
+	log := logger.NewUPPLogger("test-service", "panic")
+	internalConcordanceClient := newInternalConcordansesMock(t, "tid_test", map[string]Concept{
+		"person": {ID: "person", PrefLabel: "Person", Type: ontologyPersonType},
+		"topic":  {ID: "topic", PrefLabel: "Topic", Type: ontologyTopicType},
+		"vetoed": {ID: "vetoed", PrefLabel: "Vetoed", Type: ontologyPersonType},
+	})
+	mockConcordance := NewConcordance("internalConcordancesHost", "/internalconcordances", internalConcordanceClient)
+
+	person := Suggestion{Concept: Concept{ID: "person", PrefLabel: "Person", Type: ontologyPersonType}}
+	topic := Suggestion{Concept: Concept{ID: "topic", PrefLabel: "Topic", Type: ontologyTopicType}}
+	vetoed := Suggestion{Concept: Concept{ID: "vetoed", PrefLabel: "Vetoed", Type: ontologyPersonType}}
+	suggestions := []Suggestion{person, topic, vetoed}
+
+	suggestionApi.On("GetSuggestions", mock.AnythingOfType("[]uint8"), "tid_test", "tests_origin").Return(SuggestionsResponse{Suggestions: suggestions}, nil).Once()
+	suggestionApi.On("FilterSuggestions", suggestions).Return([]Suggestion{person, vetoed}).Once()
+
+	mockClientPublicThings := new(mockHttpClient)
+	mockClientPublicThings.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"things":{}}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	broaderProvider := NewBroaderConceptsProvider("publicThingsUrl", "/things", mockClientPublicThings)
+
+	blacklisterMock := new(mockHttpClient)
+	blacklisterMock.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":["vetoed","another"]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	blacklister := NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
+
+	observer := &recordingObserver{returned: map[string]int{}, dropped: map[string]int{}}
+	aggregateSuggester := NewAggregateSuggester(log, mockConcordance, broaderProvider, blacklister, suggestionApi)
+	aggregateSuggester.Observer = observer
+
+	response, err := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
+	expect.NoError(err)
+	expect.Equal([]Suggestion{person}, response.Suggestions)
+
+	expect.Equal(map[string]int{"Mock Suggestion API/Person": 1}, observer.returned)
+	expect.Equal(1, observer.dropped[FilterConceptType])
+	expect.Equal(1, observer.dropped[FilterBlacklist])
+	expect.Equal(0, observer.dropped[FilterUnconcorded])
+	expect.Equal(2, observer.blacklistSize)
+	suggestionApi.AssertExpectations(t)
+}
+
+func TestAggregateSuggester_GetSuggestionsRecordsSpans(t *testing.T) {
+	expect := assert.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider, previous := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+
+	suggestionApi := new(mockSuggestionApi)
+	log := logger.NewUPPLogger("test-service", "panic")
+	internalConcordanceClient := newInternalConcordansesMock(t, "tid_test", map[string]Concept{
+		"person": {ID: "person", PrefLabel: "Person", Type: ontologyPersonType},
+	})
+	mockConcordance := NewConcordance("internalConcordancesHost", "/internalconcordances", internalConcordanceClient)
+
+	person := Suggestion{Concept: Concept{ID: "person", PrefLabel: "Person", Type: ontologyPersonType}}
+	suggestionApi.On("GetSuggestions", mock.AnythingOfType("[]uint8"), "tid_test", "tests_origin").Return(SuggestionsResponse{Suggestions: []Suggestion{person}}, nil).Once()
+	suggestionApi.On("FilterSuggestions", []Suggestion{person}).Return([]Suggestion{person}).Once()
+
+	mockClientPublicThings := new(mockHttpClient)
+	mockClientPublicThings.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"things":{}}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	broaderProvider := NewBroaderConceptsProvider("publicThingsUrl", "/things", mockClientPublicThings)
+
+	blacklisterMock := new(mockHttpClient)
+	blacklisterMock.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":[]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	blacklister := NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
+
+	aggregateSuggester := NewAggregateSuggester(log, mockConcordance, broaderProvider, blacklister, suggestionApi)
+	_, err := aggregateSuggester.GetSuggestions(context.Background(), []byte{}, "tid_test", "tests_origin")
+	expect.NoError(err)
+
+	spans := recorder.Ended()
+	names := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans {
+		names[span.Name()] = span
+	}
+	root, ok := names["AggregateSuggester.GetSuggestions"]
+	if !expect.True(ok) {
+		return
+	}
+	for _, name := range []string{"ConcordanceService.getConcordances", "BroaderConceptsProvider.getBroaderConcepts", "Blacklister.GetBlacklist"} {
+		span, ok := names[name]
+		if expect.True(ok, name) {
+			expect.Equal(root.SpanContext().TraceID(), span.SpanContext().TraceID(), name)
+			expect.Equal(root.SpanContext().SpanID(), span.Parent().SpanID(), name)
+		}
+	}
+}
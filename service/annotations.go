@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	fp "path/filepath"
+)
+
+// The statuses of the suggestions compared with the existing annotations of the request.
+const (
+	AnnotationNew         = "new"
+	AnnotationPresent     = "present"
+	AnnotationConflicting = "conflicting"
+)
+
+// ExistingAnnotation is an annotation the content already has, flagged when it should not be kept.
+type ExistingAnnotation struct {
+	Suggestion
+	Blacklisted bool `json:"blacklisted"`
+	// Deprecated annotations are not concorded anymore.
+	Deprecated bool `json:"deprecated"`
+}
+
+// existingAnnotations are the annotations of the request with their concorded concepts.
+type existingAnnotations struct {
+	annotations []Suggestion
+	// concorded maps the UUIDs of the annotations to their concepts, nil when the concordance failed.
+	concorded map[string]Concept
+}
+
+// lookupExistingAnnotations concords the existing annotations. When the concordance fails, the annotations are compared
+// as they are and none of them is flagged as deprecated.
+func (s *AggregateSuggester) lookupExistingAnnotations(ctx context.Context, annotations []Suggestion, tid string) *existingAnnotations {
+	defer timingsFromContext(ctx).start(stageConcordance+"existing-annotations", "Concordance of the existing annotations")()
+	ids := []string{}
+	for _, a := range annotations {
+		ids = append(ids, fp.Base(a.ID))
+	}
+	existing := &existingAnnotations{annotations: annotations}
+	concorded, err := s.Concordance.getConcordances(ctx, dedup(ids), tid)
+	if err != nil {
+		s.Log.WithTransactionID(tid).WithError(err).Warn("Couldn't concord the existing annotations, they are neither canonicalised nor checked for deprecation")
+		return existing
+	}
+	existing.concorded = concorded.Concepts
+	if existing.concorded == nil {
+		existing.concorded = map[string]Concept{}
+	}
+	return existing
+}
+
+// flag returns the existing annotations with their concorded concept, flagging the blacklisted and deprecated ones.
+func (e *existingAnnotations) flag(blacklist Blacklist, blacklister ConceptBlacklister) []ExistingAnnotation {
+	flagged := make([]ExistingAnnotation, 0, len(e.annotations))
+	for _, a := range e.annotations {
+		annotation := ExistingAnnotation{Suggestion: a}
+		if e.concorded != nil {
+			c, ok := e.concorded[fp.Base(a.ID)]
+			if ok {
+				annotation.Concept = c
+			} else {
+				annotation.Deprecated = true
+			}
+		}
+		annotation.Blacklisted = blacklister.IsBlacklisted(annotation.ID, blacklist)
+		flagged = append(flagged, annotation)
+	}
+	return flagged
+}
+
+// classify sets the status of the suggestions: present when the content is annotated with the same concept and predicate,
+// conflicting when it is annotated with the same concept and another predicate, new otherwise.
+// Annotations without predicate match any predicate.
+func (e *existingAnnotations) classify(suggestions []Suggestion) {
+	predicates := map[string]map[string]bool{}
+	for _, a := range e.annotations {
+		id := fp.Base(a.ID)
+		if c, ok := e.concorded[id]; ok {
+			id = fp.Base(c.ID)
+		}
+		if predicates[id] == nil {
+			predicates[id] = map[string]bool{}
+		}
+		predicates[id][a.Predicate] = true
+	}
+	for i, s := range suggestions {
+		annotated, ok := predicates[fp.Base(s.ID)]
+		switch {
+		case !ok:
+			suggestions[i].Status = AnnotationNew
+		case annotated[s.Predicate] || annotated[""]:
+			suggestions[i].Status = AnnotationPresent
+		default:
+			suggestions[i].Status = AnnotationConflicting
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mentionsPredicate = "http://www.ft.com/ontology/annotation/mentions"
+	aboutPredicate    = "http://www.ft.com/ontology/annotation/about"
+)
+
+// newConcordancesClient concords the known ids only, like the internal concordances do without deprecated concepts.
+func newConcordancesClient(concepts map[string]Concept) Client {
+	return &http.Client{Transport: &mockTransport{handler: func(req *http.Request) (*http.Response, error) {
+		response := ConcordanceResponse{Concepts: map[string]Concept{}}
+		for _, id := range req.URL.Query()[idsParamName] {
+			if c, ok := concepts[id]; ok {
+				response.Concepts[id] = c
+			}
+		}
+		rec := httptest.NewRecorder()
+		err := json.NewEncoder(rec.Body).Encode(response)
+		return rec.Result(), err
+	}}}
+}
+
+func TestAggregateSuggester_GetSuggestionsWithExistingAnnotations(t *testing.T) {
+	person := Concept{ID: "http://www.ft.com/thing/person", PrefLabel: "Person", Type: ontologyPersonType}
+	topic := Concept{ID: "http://www.ft.com/thing/topic", PrefLabel: "Topic", Type: ontologyTopicType}
+	location := Concept{ID: "http://www.ft.com/thing/location", PrefLabel: "Location", Type: ontologyLocationType}
+	vetoed := Concept{ID: "http://www.ft.com/thing/vetoed", PrefLabel: "Vetoed", Type: ontologyTopicType}
+	concordance := NewConcordance("internalConcordancesHost", "/internalconcordances", newConcordancesClient(map[string]Concept{
+		"person":   person,
+		"topic":    topic,
+		"location": location,
+		"vetoed":   vetoed,
+		// the old identifier of the topic
+		"old-topic": topic,
+	}))
+	suggester := &fakeShadowSuggester{suggestions: []Suggestion{
+		{Concept: Concept{ID: "person"}, Predicate: mentionsPredicate},
+		{Concept: Concept{ID: "topic"}, Predicate: mentionsPredicate},
+		{Concept: Concept{ID: "location"}, Predicate: mentionsPredicate},
+	}, ctxErr: make(chan error, 1)}
+	publicThings := new(mockHttpClient)
+	publicThings.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"things":{}}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	blacklisterClient := new(mockHttpClient)
+	blacklisterClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":["vetoed"]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+
+	aggregateSuggester := NewAggregateSuggester(logger.NewUPPLogger("test-service", "panic"),
+		concordance,
+		NewBroaderConceptsProvider("publicThingsUrl", "/things", publicThings),
+		NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterClient),
+		suggester)
+
+	payload := `{"id":"content","existingAnnotations":[
+		{"id":"http://www.ft.com/thing/person","predicate":"` + mentionsPredicate + `"},
+		{"id":"http://www.ft.com/thing/old-topic","predicate":"` + aboutPredicate + `"},
+		{"id":"http://www.ft.com/thing/vetoed","predicate":"` + aboutPredicate + `"},
+		{"id":"http://www.ft.com/thing/gone","predicate":"` + aboutPredicate + `"}]}`
+	response, err := aggregateSuggester.GetSuggestions(context.Background(), []byte(payload), "tid_test", "tests_origin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Suggestion{
+		{Concept: person, Predicate: mentionsPredicate, Status: AnnotationPresent},
+		{Concept: topic, Predicate: mentionsPredicate, Status: AnnotationConflicting},
+		{Concept: location, Predicate: mentionsPredicate, Status: AnnotationNew},
+	}, response.Suggestions)
+	assert.Equal(t, []ExistingAnnotation{
+		{Suggestion: Suggestion{Concept: person, Predicate: mentionsPredicate}},
+		{Suggestion: Suggestion{Concept: topic, Predicate: aboutPredicate}},
+		{Suggestion: Suggestion{Concept: vetoed, Predicate: aboutPredicate}, Blacklisted: true},
+		{Suggestion: Suggestion{Concept: Concept{ID: "http://www.ft.com/thing/gone"}, Predicate: aboutPredicate}, Deprecated: true},
+	}, response.ExistingAnnotations)
+}
+
+func TestAggregateSuggester_GetSuggestionsWithoutExistingAnnotations(t *testing.T) {
+	concordance := NewConcordance("internalConcordancesHost", "/internalconcordances", newConcordancesClient(map[string]Concept{
+		"person": {ID: "http://www.ft.com/thing/person", Type: ontologyPersonType},
+	}))
+	suggester := &fakeShadowSuggester{suggestions: []Suggestion{{Concept: Concept{ID: "person"}}}, ctxErr: make(chan error, 1)}
+	publicThings := new(mockHttpClient)
+	publicThings.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"things":{}}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	blacklisterClient := new(mockHttpClient)
+	blacklisterClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":[]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+
+	aggregateSuggester := NewAggregateSuggester(logger.NewUPPLogger("test-service", "panic"),
+		concordance,
+		NewBroaderConceptsProvider("publicThingsUrl", "/things", publicThings),
+		NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterClient),
+		suggester)
+
+	response, err := aggregateSuggester.GetSuggestions(context.Background(), []byte(`{"id":"content"}`), "tid_test", "tests_origin")
+	require.NoError(t, err)
+	require.Len(t, response.Suggestions, 1)
+	assert.Empty(t, response.Suggestions[0].Status)
+	assert.Nil(t, response.ExistingAnnotations)
+}
+
+func TestExistingAnnotations_UnconcordedClassify(t *testing.T) {
+	existing := &existingAnnotations{annotations: []Suggestion{
+		{Concept: Concept{ID: "http://www.ft.com/thing/a"}, Predicate: aboutPredicate},
+		{Concept: Concept{ID: "http://www.ft.com/thing/b"}},
+	}}
+	suggestions := []Suggestion{
+		{Concept: Concept{ID: "http://www.ft.com/thing/a"}, Predicate: mentionsPredicate},
+		{Concept: Concept{ID: "http://www.ft.com/thing/b"}, Predicate: mentionsPredicate},
+		{Concept: Concept{ID: "http://www.ft.com/thing/c"}, Predicate: mentionsPredicate},
+	}
+	existing.classify(suggestions)
+	assert.Equal(t, AnnotationConflicting, suggestions[0].Status)
+	assert.Equal(t, AnnotationPresent, suggestions[1].Status, "an annotation without predicate matches any predicate")
+	assert.Equal(t, AnnotationNew, suggestions[2].Status)
+
+	flagged := existing.flag(Blacklist{}, NewConceptBlacklister("", "", nil))
+	assert.False(t, flagged[0].Deprecated, "nothing is deprecated when the concordance failed")
+}
+
+func TestLookupExistingAnnotations_ConcordanceError(t *testing.T) {
+	client := new(mockHttpClient)
+	client.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{}, errors.New("connection refused"))
+	s := &AggregateSuggester{Log: logger.NewUPPLogger("test-service", "panic"), Concordance: NewConcordance("host", "/internalconcordances", client)}
+
+	existing := s.lookupExistingAnnotations(context.Background(), []Suggestion{{Concept: Concept{ID: "a"}}}, "tid_test")
+	assert.Nil(t, existing.concorded)
+	assert.Len(t, existing.annotations, 1)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -12,7 +13,7 @@ import (
 
 type ConceptBlacklister interface {
 	IsBlacklisted(uuid string, bl Blacklist) bool
-	GetBlacklist(tid string) (Blacklist, error)
+	GetBlacklist(ctx context.Context, tid string) (Blacklist, error)
 	Check() v1_1.Check
 }
 
@@ -49,8 +50,14 @@ func (b *Blacklister) IsBlacklisted(conceptId string, bl Blacklist) bool {
 	return false
 }
 
-func (b *Blacklister) GetBlacklist(tid string) (Blacklist, error) {
-	req, err := http.NewRequest("GET", b.baseUrl+b.endpoint, nil)
+func (b *Blacklister) GetBlacklist(ctx context.Context, tid string) (_ Blacklist, err error) {
+	ctx, span := tracer().Start(ctx, "Blacklister.GetBlacklist")
+	defer func() {
+		recordSpanError(span, err)
+		span.End()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", b.baseUrl+b.endpoint, nil)
 	if err != nil {
 		return Blacklist{}, err
 	}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -9,6 +10,8 @@ import (
 	"strings"
 
 	"github.com/Financial-Times/go-fthealth/v1_1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type BroaderConceptsProvider struct {
@@ -77,7 +80,7 @@ func (b *BroaderConceptsProvider) healthCheck() (string, error) {
 	return fmt.Sprintf("%v is healthy", b.name), nil
 }
 
-func (b *BroaderConceptsProvider) excludeBroaderConceptsFromResponse(suggestions map[int][]Suggestion, tid string) (map[int][]Suggestion, error) {
+func (b *BroaderConceptsProvider) excludeBroaderConceptsFromResponse(ctx context.Context, suggestions map[int][]Suggestion, tid string) (map[int][]Suggestion, error) {
 	var ids []string
 	for _, sourceSuggestions := range suggestions {
 		for _, suggestion := range sourceSuggestions {
@@ -90,7 +93,7 @@ func (b *BroaderConceptsProvider) excludeBroaderConceptsFromResponse(suggestions
 	}
 
 	results := make(map[int][]Suggestion)
-	broader, err := b.getBroaderConcepts(ids, tid)
+	broader, err := b.getBroaderConcepts(ctx, ids, tid)
 	if err != nil {
 		return suggestions, err
 	}
@@ -119,10 +122,16 @@ func (b *BroaderConceptsProvider) excludeBroaderConceptsFromResponse(suggestions
 	return results, nil
 }
 
-func (b *BroaderConceptsProvider) getBroaderConcepts(ids []string, tid string) (*broaderResponse, error) {
+func (b *BroaderConceptsProvider) getBroaderConcepts(ctx context.Context, ids []string, tid string) (_ *broaderResponse, err error) {
+	ctx, span := tracer().Start(ctx, "BroaderConceptsProvider.getBroaderConcepts", trace.WithAttributes(attribute.Int("broader.ids", len(ids))))
+	defer func() {
+		recordSpanError(span, err)
+		span.End()
+	}()
+
 	var result broaderResponse
 	preparedURL := fmt.Sprintf("%s/%s", strings.TrimRight(b.PublicThingsBaseURL, "/"), strings.Trim(b.PublicThingsEndpoint, "/"))
-	req, err := http.NewRequest("GET", preparedURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", preparedURL, nil)
 	if err != nil {
 		return nil, err
 	}
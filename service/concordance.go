@@ -1,12 +1,15 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 
 	"github.com/Financial-Times/go-fthealth/v1_1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const idsParamName = "ids"
@@ -68,9 +71,14 @@ func (concordance *ConcordanceService) healthCheck() (string, error) {
 	return fmt.Sprintf("%v is healthy", concordance.name), nil
 }
 
-func (concordance *ConcordanceService) getConcordances(ids []string, tid string) (ConcordanceResponse, error) {
-	var concorded ConcordanceResponse
-	req, err := http.NewRequest("GET", concordance.ConcordanceBaseURL+concordance.ConcordanceEndpoint, nil)
+func (concordance *ConcordanceService) getConcordances(ctx context.Context, ids []string, tid string) (concorded ConcordanceResponse, err error) {
+	ctx, span := tracer().Start(ctx, "ConcordanceService.getConcordances", trace.WithAttributes(attribute.Int("concordance.ids", len(ids))))
+	defer func() {
+		recordSpanError(span, err)
+		span.End()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", concordance.ConcordanceBaseURL+concordance.ConcordanceEndpoint, nil)
 	if err != nil {
 		return concorded, err
 	}
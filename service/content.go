@@ -0,0 +1,53 @@
+package service
+
+import (
+	"encoding/json"
+	fp "path/filepath"
+)
+
+// payloadContent is what the aggregation reads from the content payload, the payload is otherwise opaque.
+type payloadContent struct {
+	ID   string `json:"id"`
+	UUID string `json:"uuid"`
+	Type string `json:"type"`
+	// ExistingAnnotations are the annotations the content already has, only their id and predicate are read.
+	ExistingAnnotations []Suggestion `json:"existingAnnotations"`
+}
+
+func parseContent(payload []byte) payloadContent {
+	var content payloadContent
+	if err := json.Unmarshal(payload, &content); err != nil {
+		return payloadContent{}
+	}
+	return content
+}
+
+// contentID returns the id, or else the uuid, of the content.
+func (c payloadContent) contentID() string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return c.UUID
+}
+
+// contentType returns the short name of the content type, e.g. "Article", empty when the payload has none.
+func (c payloadContent) contentType() string {
+	if c.Type == "" {
+		return ""
+	}
+	return fp.Base(c.Type)
+}
+
+// contentUUID returns the UUID of the content, the same whether the payload has its id URL or only its uuid.
+func (c payloadContent) contentUUID() string {
+	id := c.contentID()
+	if id == "" {
+		return ""
+	}
+	return fp.Base(id)
+}
+
+// ContentUUID returns the UUID of the content of the payload, taken from its id or uuid field, empty when it has none.
+func ContentUUID(payload []byte) string {
+	return parseContent(payload).contentUUID()
+}
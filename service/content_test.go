@@ -0,0 +1,13 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentUUID(t *testing.T) {
+	assert.Equal(t, "f758ef56-c40a-3162-91aa-3e8a3aabc495", ContentUUID([]byte(`{"id":"http://www.ft.com/thing/f758ef56-c40a-3162-91aa-3e8a3aabc495"}`)))
+	assert.Equal(t, "f758ef56-c40a-3162-91aa-3e8a3aabc495", ContentUUID([]byte(`{"uuid":"f758ef56-c40a-3162-91aa-3e8a3aabc495"}`)))
+	assert.Equal(t, "", ContentUUID([]byte(`{"title":"no id"}`)))
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	fp "path/filepath"
+
+	"github.com/Financial-Times/go-fthealth/v1_1"
+	"github.com/Financial-Times/public-suggestions-api/reqorigin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContentNotFoundError is returned by a ContentReader when the content does not exist.
+var ContentNotFoundError = errors.New("content not found")
+
+// ContentReader fetches the JSON of stored content, to suggest annotations for it.
+type ContentReader interface {
+	GetContent(ctx context.Context, uuid, tid, origin string) ([]byte, error)
+}
+
+// ContentAPI reads the content from a content read API, e.g. content-public-read.
+type ContentAPI struct {
+	baseURL       string
+	endpoint      string
+	client        Client
+	systemID      string
+	name          string
+	failureImpact string
+}
+
+// NewContentAPI creates a ContentAPI fetching the content from baseURL + endpoint + "/" + uuid.
+func NewContentAPI(baseURL, endpoint string, client Client) *ContentAPI {
+	return &ContentAPI{
+		baseURL:       baseURL,
+		endpoint:      endpoint,
+		client:        client,
+		systemID:      "content-public-read",
+		name:          "content-public-read",
+		failureImpact: "Suggesting annotations for stored content won't work",
+	}
+}
+
+func (c *ContentAPI) GetContent(ctx context.Context, uuid, tid, origin string) (_ []byte, err error) {
+	ctx, span := tracer().Start(ctx, "ContentAPI.GetContent", trace.WithAttributes(attribute.String("content.uuid", uuid)))
+	defer func() {
+		recordSpanError(span, err)
+		span.End()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+c.endpoint+"/"+uuid, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", "UPP public-suggestions-api")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("X-Request-Id", tid)
+	reqorigin.SetHeader(req, origin)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ContentNotFoundError
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v returned HTTP %v", c.name, resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (c *ContentAPI) Check() v1_1.Check {
+	return v1_1.Check{
+		ID:               c.systemID,
+		BusinessImpact:   c.failureImpact,
+		Name:             fmt.Sprintf("%v Healthcheck", c.name),
+		PanicGuide:       PanicGuideURL + c.systemID,
+		Severity:         3,
+		TechnicalSummary: fmt.Sprintf("%v is not available", c.name),
+		Checker:          c.healthCheck,
+	}
+}
+
+func (c *ContentAPI) healthCheck() (string, error) {
+	req, err := http.NewRequest("GET", c.baseURL+"/__gtg", nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Add("User-Agent", "UPP public-suggestions-api")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Health check returned a non-200 HTTP status: %v", resp.StatusCode)
+	}
+	return fmt.Sprintf("%v is healthy", c.name), nil
+}
+
+// ContentDir is a local stand-in for the content read API, it reads the content from the <uuid>.json files of a directory.
+type ContentDir string
+
+func (d ContentDir) GetContent(_ context.Context, uuid, _, _ string) ([]byte, error) {
+	content, err := ioutil.ReadFile(fp.Join(string(d), fp.Base(uuid)+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ContentNotFoundError
+	}
+	return content, err
+}
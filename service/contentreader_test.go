@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentAPI_GetContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tid_test", r.Header.Get("X-Request-Id"))
+		assert.Equal(t, "tests_origin", r.Header.Get("X-Origin"))
+		switch r.URL.Path {
+		case "/content/f758ef56-c40a-3162-91aa-3e8a3aabc495":
+			w.Write([]byte(`{"uuid":"f758ef56-c40a-3162-91aa-3e8a3aabc495"}`))
+		case "/content/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	contentAPI := NewContentAPI(server.URL, "/content", http.DefaultClient)
+
+	content, err := contentAPI.GetContent(context.Background(), "f758ef56-c40a-3162-91aa-3e8a3aabc495", "tid_test", "tests_origin")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"uuid":"f758ef56-c40a-3162-91aa-3e8a3aabc495"}`, string(content))
+
+	_, err = contentAPI.GetContent(context.Background(), "unknown", "tid_test", "tests_origin")
+	assert.Equal(t, ContentNotFoundError, err)
+
+	_, err = contentAPI.GetContent(context.Background(), "broken", "tid_test", "tests_origin")
+	assert.EqualError(t, err, "content-public-read returned HTTP 500")
+}
+
+func TestContentAPI_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/__gtg", r.URL.Path)
+	}))
+	defer server.Close()
+
+	check := NewContentAPI(server.URL, "/content", http.DefaultClient).Check()
+	assert.Equal(t, "content-public-read", check.ID)
+	assert.Equal(t, uint8(3), check.Severity)
+	message, err := check.Checker()
+	assert.NoError(t, err)
+	assert.Equal(t, "content-public-read is healthy", message)
+}
+
+func TestContentDir_GetContent(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "f758ef56-c40a-3162-91aa-3e8a3aabc495.json"), []byte(`{"title":"stored"}`), os.ModePerm))
+
+	content, err := ContentDir(dir).GetContent(context.Background(), "f758ef56-c40a-3162-91aa-3e8a3aabc495", "tid_test", "")
+	require.NoError(t, err)
+	assert.Equal(t, `{"title":"stored"}`, string(content))
+
+	_, err = ContentDir(dir).GetContent(context.Background(), "unknown", "tid_test", "")
+	assert.Equal(t, ContentNotFoundError, err)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+// UnknownVariantError is returned for a request forced into a variant the Experiment does not have.
+var UnknownVariantError = errors.New("unknown experiment variant")
+
+// Variant is one arm of an Experiment.
+type Variant struct {
+	Name string
+	// Weight is the share of the traffic assigned to the variant, relative to the other variants.
+	Weight int
+	// Suggesters replace the Suggesters of the AggregateSuggester when set.
+	Suggesters []Suggester
+	// SkipFilters are the FilterBroader, FilterBlacklist and FilterSuppressed filters the variant does not apply.
+	SkipFilters []string
+}
+
+// skips is false on a nil *Variant, so requests outside of an experiment apply all the filters.
+func (v *Variant) skips(filter string) bool {
+	if v == nil {
+		return false
+	}
+	for _, f := range v.SkipFilters {
+		if f == filter {
+			return true
+		}
+	}
+	return false
+}
+
+// Experiment splits the requests between variants. The assignment is sticky by content ID:
+// all the requests for a piece of content get the same variant as long as the variants do not change.
+type Experiment struct {
+	Name        string
+	Variants    []Variant
+	totalWeight uint32
+}
+
+// NewExperiment validates the variants of an experiment.
+func NewExperiment(name string, variants ...Variant) (*Experiment, error) {
+	if name == "" {
+		return nil, errors.New("experiment has no name")
+	}
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("experiment %s has no variants", name)
+	}
+	e := &Experiment{Name: name, Variants: variants}
+	names := map[string]bool{}
+	for _, v := range variants {
+		if v.Name == "" {
+			return nil, fmt.Errorf("experiment %s has a variant without name", name)
+		}
+		if names[v.Name] {
+			return nil, fmt.Errorf("experiment %s has several %s variants", name, v.Name)
+		}
+		names[v.Name] = true
+		if v.Weight < 0 {
+			return nil, fmt.Errorf("variant %s of experiment %s has a negative weight", v.Name, name)
+		}
+		for _, f := range v.SkipFilters {
+			if f != FilterBroader && f != FilterBlacklist && f != FilterSuppressed {
+				return nil, fmt.Errorf("variant %s of experiment %s skips unknown filter %q, only %s, %s and %s can be skipped", v.Name, name, f, FilterBroader, FilterBlacklist, FilterSuppressed)
+			}
+		}
+		e.totalWeight += uint32(v.Weight)
+	}
+	if e.totalWeight == 0 {
+		return nil, fmt.Errorf("experiment %s has no traffic", name)
+	}
+	return e, nil
+}
+
+// Assign returns the variant of a request. key is the content ID, or the transaction ID for content without ID.
+func (e *Experiment) Assign(key string) *Variant {
+	h := fnv.New32a()
+	h.Write([]byte(e.Name + "/" + key))
+	bucket := h.Sum32() % e.totalWeight
+	for i := range e.Variants {
+		if bucket < uint32(e.Variants[i].Weight) {
+			return &e.Variants[i]
+		}
+		bucket -= uint32(e.Variants[i].Weight)
+	}
+	return &e.Variants[len(e.Variants)-1]
+}
+
+// Variant returns the variant with the name, nil when the experiment has none.
+func (e *Experiment) Variant(name string) *Variant {
+	for i := range e.Variants {
+		if e.Variants[i].Name == name {
+			return &e.Variants[i]
+		}
+	}
+	return nil
+}
+
+type variantKey struct{}
+
+// ContextWithVariant returns a context that makes the AggregateSuggester use the variant with the name instead of
+// assigning one, e.g. to compare two variants on the same content.
+func ContextWithVariant(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, variantKey{}, name)
+}
+
+func variantFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(variantKey{}).(string)
+	return name
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExperiment_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		variants []Variant
+		err      string
+	}{
+		{name: "", variants: []Variant{{Name: "a", Weight: 1}}, err: "experiment has no name"},
+		{name: "e", err: "experiment e has no variants"},
+		{name: "e", variants: []Variant{{Weight: 1}}, err: "experiment e has a variant without name"},
+		{name: "e", variants: []Variant{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}, err: "experiment e has several a variants"},
+		{name: "e", variants: []Variant{{Name: "a", Weight: -1}}, err: "variant a of experiment e has a negative weight"},
+		{name: "e", variants: []Variant{{Name: "a"}, {Name: "b"}}, err: "experiment e has no traffic"},
+		{name: "e", variants: []Variant{{Name: "a", Weight: 1, SkipFilters: []string{FilterConceptType}}}, err: `variant a of experiment e skips unknown filter "type", only broader, blacklist and suppressed can be skipped`},
+	}
+	for _, tc := range testCases {
+		_, err := NewExperiment(tc.name, tc.variants...)
+		assert.EqualError(t, err, tc.err)
+	}
+}
+
+func TestExperiment_Assign(t *testing.T) {
+	e, err := NewExperiment("e", Variant{Name: "control", Weight: 3}, Variant{Name: "off", Weight: 0}, Variant{Name: "treatment", Weight: 1})
+	require.NoError(t, err)
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		key := fmt.Sprintf("content-%d", i)
+		v := e.Assign(key)
+		assert.Equal(t, v, e.Assign(key), "the assignment is sticky")
+		counts[v.Name]++
+	}
+	assert.Zero(t, counts["off"])
+	assert.InDelta(t, 3000, counts["control"], 200)
+	assert.InDelta(t, 1000, counts["treatment"], 200)
+}
+
+func TestAggregateSuggester_AssignVariantByContentUUID(t *testing.T) {
+	e, err := NewExperiment("e", Variant{Name: "control", Weight: 2}, Variant{Name: "treatment", Weight: 1})
+	require.NoError(t, err)
+	s := &AggregateSuggester{Experiment: e}
+
+	for i := 0; i < 100; i++ {
+		uuid := fmt.Sprintf("f758ef56-c40a-3162-91aa-%012d", i)
+		byID, err := s.assignVariant(context.Background(), parseContent([]byte(`{"id":"http://www.ft.com/thing/`+uuid+`"}`)), "tid_1")
+		require.NoError(t, err)
+		byUUID, err := s.assignVariant(context.Background(), parseContent([]byte(`{"uuid":"`+uuid+`"}`)), "tid_2")
+		require.NoError(t, err)
+		assert.Equal(t, byID.Name, byUUID.Name, "the content %s gets the same variant by id or uuid", uuid)
+	}
+}
+
+func TestAggregateSuggester_AssignVariantFromContext(t *testing.T) {
+	e, err := NewExperiment("e", Variant{Name: "control", Weight: 1}, Variant{Name: "off", Weight: 0})
+	require.NoError(t, err)
+	s := &AggregateSuggester{Experiment: e}
+
+	variant, err := s.assignVariant(ContextWithVariant(context.Background(), "off"), parseContent([]byte(`{"uuid":"content"}`)), "tid_test")
+	require.NoError(t, err)
+	assert.Equal(t, "off", variant.Name, "the variant of the context is used even without traffic")
+
+	_, err = s.assignVariant(ContextWithVariant(context.Background(), "other"), parseContent([]byte(`{"uuid":"content"}`)), "tid_test")
+	assert.ErrorIs(t, err, UnknownVariantError)
+	_, err = (&AggregateSuggester{}).assignVariant(ContextWithVariant(context.Background(), "off"), parseContent([]byte(`{}`)), "tid_test")
+	assert.EqualError(t, err, `unknown experiment variant "off"`)
+}
+
+func TestParseContent(t *testing.T) {
+	assert.Equal(t, "id", parseContent([]byte(`{"id":"id","uuid":"uuid"}`)).contentID())
+	assert.Equal(t, "uuid", parseContent([]byte(`{"uuid":"uuid"}`)).contentID())
+	assert.Equal(t, "", parseContent([]byte(`{"id":1}`)).contentID())
+	assert.Equal(t, "Article", parseContent([]byte(`{"type":"http://www.ft.com/ontology/content/Article"}`)).contentType())
+	assert.Equal(t, "", parseContent([]byte(`{}`)).contentType())
+}
+
+func TestAggregateSuggester_GetSuggestionsWithExperiment(t *testing.T) {
+	person := Suggestion{Concept: Concept{ID: "person", PrefLabel: "Person", Type: ontologyPersonType}}
+	vetoed := Suggestion{Concept: Concept{ID: "vetoed", PrefLabel: "Vetoed", Type: ontologyPersonType}}
+	concordance := NewConcordance("internalConcordancesHost", "/internalconcordances", newInternalConcordansesMock(t, "tid_test", map[string]Concept{
+		"person": person.Concept,
+		"vetoed": vetoed.Concept,
+	}))
+
+	// the control Suggester is not called in the treatment
+	control := new(mockSuggestionApi)
+	treatment := &fakeShadowSuggester{suggestions: []Suggestion{person, vetoed}, ctxErr: make(chan error, 1)}
+
+	publicThings := new(mockHttpClient)
+	blacklisterClient := new(mockHttpClient)
+	blacklisterClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":["vetoed"]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+
+	aggregateSuggester := NewAggregateSuggester(logger.NewUPPLogger("test-service", "panic"),
+		concordance,
+		NewBroaderConceptsProvider("publicThingsUrl", "/things", publicThings),
+		NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterClient),
+		control)
+	experiment, err := NewExperiment("e",
+		Variant{Name: "control", Weight: 0},
+		Variant{Name: "treatment", Weight: 1, Suggesters: []Suggester{treatment}, SkipFilters: []string{FilterBroader, FilterBlacklist}})
+	require.NoError(t, err)
+	aggregateSuggester.Experiment = experiment
+
+	report := &Report{}
+	response, err := aggregateSuggester.GetSuggestions(ContextWithReport(context.Background(), report), []byte(`{"id":"content"}`), "tid_test", "tests_origin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Suggestion{person, vetoed}, response.Suggestions)
+	experimentName, variant := report.Variant()
+	assert.Equal(t, "e", experimentName)
+	assert.Equal(t, "treatment", variant)
+	assert.Empty(t, report.Dropped())
+	control.AssertNotCalled(t, "GetSuggestions", mock.Anything, mock.Anything, mock.Anything)
+	publicThings.AssertNotCalled(t, "Do", mock.Anything)
+}
@@ -0,0 +1,59 @@
+package service
+
+import "github.com/Financial-Times/public-suggestions-api/content"
+
+// GroupOther holds the suggestions of a type none of the groups is for.
+const GroupOther = "other"
+
+// SuggestionGroupNames are the groups of a GroupedSuggestionsResponse, in order, named as the source params.
+var SuggestionGroupNames = []string{PseudoConceptTypeAuthor, PersonSourceParam, OrganisationSourceParam, LocationSourceParam, TopicSourceParam}
+
+// GroupedSuggestionsResponse is a SuggestionsResponse with its suggestions grouped by concept type and predicate.
+type GroupedSuggestionsResponse struct {
+	Groups []SuggestionGroup `json:"groups"`
+	// Count is the number of suggestions of all the groups.
+	Count               int                      `json:"count"`
+	Timings             []StageTiming            `json:"timings,omitempty"`
+	ExistingAnnotations []ExistingAnnotation     `json:"existingAnnotations,omitempty"`
+	Transformations     []content.Transformation `json:"transformations,omitempty"`
+}
+
+// SuggestionGroup has the suggestions of a group in the order of the response.
+type SuggestionGroup struct {
+	Name        string       `json:"name"`
+	Count       int          `json:"count"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Grouped returns the response with every group of SuggestionGroupNames, even empty, followed by the GroupOther group
+// when some suggestions are in none of them.
+func (r SuggestionsResponse) Grouped() GroupedSuggestionsResponse {
+	groups := make([]SuggestionGroup, len(SuggestionGroupNames))
+	for i, name := range SuggestionGroupNames {
+		groups[i] = SuggestionGroup{Name: name, Suggestions: []Suggestion{}}
+	}
+	other := SuggestionGroup{Name: GroupOther}
+
+	for _, suggestion := range r.Suggestions {
+		group := &other
+		for i, name := range SuggestionGroupNames {
+			if typeValidators[name](suggestion) {
+				group = &groups[i]
+				break
+			}
+		}
+		group.Suggestions = append(group.Suggestions, suggestion)
+		group.Count++
+	}
+	if other.Count > 0 {
+		groups = append(groups, other)
+	}
+
+	return GroupedSuggestionsResponse{
+		Groups:              groups,
+		Count:               len(r.Suggestions),
+		Timings:             r.Timings,
+		ExistingAnnotations: r.ExistingAnnotations,
+		Transformations:     r.Transformations,
+	}
+}
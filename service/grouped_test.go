@@ -0,0 +1,46 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Financial-Times/public-suggestions-api/content"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestionsResponse_Grouped(t *testing.T) {
+	author := Suggestion{Predicate: predicateHasAuthor, Concept: Concept{ID: "author", Type: ontologyPersonType}}
+	person := Suggestion{Concept: Concept{ID: "person", Type: ontologyPersonType}}
+	company := Suggestion{Concept: Concept{ID: "company", Type: ontologyPublicCompanyType}}
+	organisation := Suggestion{Concept: Concept{ID: "organisation", Type: ontologyOrganisationType}}
+	topic := Suggestion{Concept: Concept{ID: "topic", Type: ontologyTopicType}}
+	brand := Suggestion{Concept: Concept{ID: "brand", Type: "http://www.ft.com/ontology/product/Brand"}}
+	transformations := []content.Transformation{{Field: "bodyXML", Transformation: content.TransformationTruncated, OriginalLength: 10, Length: 5}}
+
+	grouped := SuggestionsResponse{
+		Suggestions:     []Suggestion{topic, company, person, brand, author, organisation},
+		Transformations: transformations,
+	}.Grouped()
+
+	assert.Equal(t, GroupedSuggestionsResponse{
+		Groups: []SuggestionGroup{
+			{Name: "author", Count: 1, Suggestions: []Suggestion{author}},
+			{Name: "personSource", Count: 1, Suggestions: []Suggestion{person}},
+			{Name: "organisationSource", Count: 2, Suggestions: []Suggestion{company, organisation}},
+			{Name: "locationSource", Count: 0, Suggestions: []Suggestion{}},
+			{Name: "topicSource", Count: 1, Suggestions: []Suggestion{topic}},
+			{Name: "other", Count: 1, Suggestions: []Suggestion{brand}},
+		},
+		Count:           6,
+		Transformations: transformations,
+	}, grouped)
+}
+
+func TestSuggestionsResponse_GroupedEmpty(t *testing.T) {
+	grouped, err := json.Marshal(SuggestionsResponse{Suggestions: []Suggestion{}}.Grouped())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"count":0,"groups":[{"name":"author","count":0,"suggestions":[]},{"name":"personSource","count":0,"suggestions":[]},`+
+		`{"name":"organisationSource","count":0,"suggestions":[]},{"name":"locationSource","count":0,"suggestions":[]},`+
+		`{"name":"topicSource","count":0,"suggestions":[]}]}`, string(grouped))
+}
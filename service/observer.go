@@ -0,0 +1,39 @@
+package service
+
+import fp "path/filepath"
+
+const (
+	FilterUnconcorded = "unconcorded"
+	FilterConceptType = "type"
+	FilterBroader     = "broader"
+	FilterBlacklist   = "blacklist"
+	FilterSuppressed  = "suppressed"
+)
+
+// Observer is notified by the AggregateSuggester about what happened to the suggestions of a request.
+// It is used to instrument the aggregation without coupling the service package to a metrics library.
+type Observer interface {
+	// SuggestionsReturned is called with the number of suggestions of the given source and concept type in the response.
+	SuggestionsReturned(source, conceptType string, count int)
+	// SuggestionsDropped is called with the number of candidates removed by the given filter.
+	SuggestionsDropped(filter string, count int)
+	// BlacklistFetched is called with the number of blacklisted concepts every time the blacklist is retrieved.
+	BlacklistFetched(size int)
+	// ShadowCompared is called with the overlap of a shadow Suggester with the response, for one concept type.
+	ShadowCompared(shadow, conceptType string, shared, shadowOnly, liveOnly int)
+}
+
+type noopObserver struct{}
+
+func (noopObserver) SuggestionsReturned(string, string, int)      {}
+func (noopObserver) SuggestionsDropped(string, int)               {}
+func (noopObserver) BlacklistFetched(int)                         {}
+func (noopObserver) ShadowCompared(string, string, int, int, int) {}
+
+// ConceptTypeName returns the short name of the suggestion concept type, e.g. "Person" or "Topic".
+func ConceptTypeName(s Suggestion) string {
+	if s.Type == "" {
+		return "unknown"
+	}
+	return fp.Base(s.Type)
+}
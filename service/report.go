@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// SourceReport holds the suggestions a Suggester returned before any enrichment or filtering,
+// and the ones it contributed to the response after the filters.
+type SourceReport struct {
+	Source      string       `json:"source"`
+	Suggestions []Suggestion `json:"suggestions"`
+	Returned    []Suggestion `json:"returned"`
+	Error       string       `json:"error,omitempty"`
+	index       int
+	failed      bool
+}
+
+// Failed reports if the Suggester failed, a Suggester without suggestions for the content did not fail.
+func (sr SourceReport) Failed() bool {
+	return sr.failed
+}
+
+// DroppedSuggestion is a candidate that was removed from the response, together with the filter that removed it.
+type DroppedSuggestion struct {
+	Suggestion
+	Source string `json:"source"`
+	Filter string `json:"filter"`
+}
+
+// Report collects what happened to the suggestions of a request. It is safe for concurrent use.
+type Report struct {
+	mu         sync.Mutex
+	sources    []SourceReport
+	dropped    []DroppedSuggestion
+	experiment string
+	variant    string
+}
+
+type reportKey struct{}
+
+// ContextWithReport returns a context that makes the AggregateSuggester record the raw and dropped suggestions into r.
+func ContextWithReport(ctx context.Context, r *Report) context.Context {
+	return context.WithValue(ctx, reportKey{}, r)
+}
+
+func reportFromContext(ctx context.Context) *Report {
+	r, _ := ctx.Value(reportKey{}).(*Report)
+	return r
+}
+
+// Sources returns the raw suggestions of every Suggester, in the order the Suggesters are configured.
+func (r *Report) Sources() []SourceReport {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sources := make([]SourceReport, len(r.sources))
+	copy(sources, r.sources)
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].index < sources[j].index
+	})
+	return sources
+}
+
+// Dropped returns the candidates removed by the filters.
+func (r *Report) Dropped() []DroppedSuggestion {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dropped := make([]DroppedSuggestion, len(r.dropped))
+	copy(dropped, r.dropped)
+	return dropped
+}
+
+// Variant returns the Experiment and the variant the request was assigned to, empty without Experiment.
+func (r *Report) Variant() (experiment, variant string) {
+	if r == nil {
+		return "", ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.experiment, r.variant
+}
+
+func (r *Report) setVariant(experiment, variant string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.experiment, r.variant = experiment, variant
+}
+
+func (r *Report) addSource(index int, source string, suggestions []Suggestion, err error) {
+	if r == nil {
+		return
+	}
+	sr := SourceReport{Source: source, Suggestions: suggestions, Returned: []Suggestion{}, index: index}
+	if sr.Suggestions == nil {
+		sr.Suggestions = []Suggestion{}
+	}
+	if err != nil {
+		sr.Error = err.Error()
+		sr.failed = !errors.Is(err, NoContentError)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = append(r.sources, sr)
+}
+
+func (r *Report) addReturned(index int, suggestions []Suggestion) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.sources {
+		if r.sources[i].index == index {
+			r.sources[i].Returned = suggestions
+			return
+		}
+	}
+}
+
+func (r *Report) addDropped(source, filter string, suggestions []Suggestion) {
+	if r == nil || len(suggestions) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range suggestions {
+		r.dropped = append(r.dropped, DroppedSuggestion{Suggestion: s, Source: source, Filter: filter})
+	}
+}
+
+// difference returns the suggestions of before that are missing from after, comparing them by concept and predicate.
+func difference(before, after []Suggestion) []Suggestion {
+	kept := map[Suggestion]int{}
+	for _, s := range after {
+		kept[s]++
+	}
+	var removed []Suggestion
+	for _, s := range before {
+		if kept[s] > 0 {
+			kept[s]--
+			continue
+		}
+		removed = append(removed, s)
+	}
+	return removed
+}
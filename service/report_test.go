@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateSuggester_GetSuggestionsFillsReport(t *testing.T) {
+	known := map[string]Concept{
+		"person":   {ID: "http://www.ft.com/thing/person", PrefLabel: "Person", Type: ontologyPersonType},
+		"location": {ID: "http://www.ft.com/thing/location", PrefLabel: "Location", Type: ontologyLocationType},
+		"broad":    {ID: "http://www.ft.com/thing/broad", PrefLabel: "Broad", Type: ontologyLocationType},
+		"vetoed":   {ID: "http://www.ft.com/thing/vetoed", PrefLabel: "Vetoed", Type: ontologyPersonType},
+	}
+	// only the known concepts are concorded
+	concordanceClient := &http.Client{Transport: &mockTransport{handler: func(req *http.Request) (*http.Response, error) {
+		resp := ConcordanceResponse{Concepts: map[string]Concept{}}
+		for _, id := range req.URL.Query()[idsParamName] {
+			if c, ok := known[id]; ok {
+				resp.Concepts[id] = c
+			}
+		}
+		rec := httptest.NewRecorder()
+		require.NoError(t, json.NewEncoder(rec.Body).Encode(resp))
+		return rec.Result(), nil
+	}}}
+
+	raw := []Suggestion{
+		{Concept: Concept{ID: "http://www.ft.com/thing/person"}},
+		{Concept: Concept{ID: "http://www.ft.com/thing/location"}},
+		{Concept: Concept{ID: "http://www.ft.com/thing/broad"}},
+		{Concept: Concept{ID: "http://www.ft.com/thing/vetoed"}},
+		{Concept: Concept{ID: "http://www.ft.com/thing/unknown"}},
+	}
+	person := Suggestion{Concept: known["person"]}
+	location := Suggestion{Concept: known["location"]}
+	broad := Suggestion{Concept: known["broad"]}
+	vetoed := Suggestion{Concept: known["vetoed"]}
+	enriched := []Suggestion{person, location, broad, vetoed}
+
+	suggestionApi := new(mockSuggestionApi)
+	suggestionApi.On("GetSuggestions", mock.AnythingOfType("[]uint8"), "tid_test", "tests_origin").Return(SuggestionsResponse{Suggestions: raw}, nil).Once()
+	suggestionApi.On("FilterSuggestions", enriched).Return([]Suggestion{person, broad, vetoed}).Once()
+
+	publicThings := new(mockHttpClient)
+	publicThings.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"things":{"person":{"id":"person","broaderConcepts":[{"id":"http://www.ft.com/thing/broad"}]}}}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	blacklisterClient := new(mockHttpClient)
+	blacklisterClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":["vetoed"]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+
+	aggregateSuggester := NewAggregateSuggester(logger.NewUPPLogger("test-service", "panic"),
+		NewConcordance("internalConcordancesHost", "/internalconcordances", concordanceClient),
+		NewBroaderConceptsProvider("publicThingsUrl", "/things", publicThings),
+		NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterClient),
+		suggestionApi)
+
+	report := &Report{}
+	response, err := aggregateSuggester.GetSuggestions(ContextWithReport(context.Background(), report), []byte{}, "tid_test", "tests_origin")
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{person}, response.Suggestions)
+
+	assert.Equal(t, []SourceReport{{Source: "Mock Suggestion API", Suggestions: raw, Returned: []Suggestion{person}}}, report.Sources())
+	assert.Equal(t, []DroppedSuggestion{
+		{Suggestion: raw[4], Source: "Mock Suggestion API", Filter: FilterUnconcorded},
+		{Suggestion: location, Source: "Mock Suggestion API", Filter: FilterConceptType},
+		{Suggestion: broad, Source: "Mock Suggestion API", Filter: FilterBroader},
+		{Suggestion: vetoed, Source: "Mock Suggestion API", Filter: FilterBlacklist},
+	}, report.Dropped())
+	suggestionApi.AssertExpectations(t)
+}
+
+func TestReport_NilIsNoop(t *testing.T) {
+	var report *Report
+	report.addSource(0, "source", nil, nil)
+	report.addDropped("source", FilterBlacklist, []Suggestion{{}})
+	report.addReturned(0, []Suggestion{{}})
+	assert.Nil(t, report.Sources())
+	assert.Nil(t, report.Dropped())
+}
+
+func TestReport_SourceFailed(t *testing.T) {
+	report := &Report{}
+	report.addSource(0, "ok", nil, nil)
+	report.addSource(1, "no content", nil, NoContentError)
+	report.addSource(2, "failed", nil, &SuggesterErr{msg: "Suggestion API returned HTTP 503"})
+	sources := report.Sources()
+	assert.False(t, sources[0].Failed())
+	assert.False(t, sources[1].Failed())
+	assert.Equal(t, "Suggestion API returned HTTP 204", sources[1].Error)
+	assert.True(t, sources[2].Failed())
+}
+
+func TestDifference(t *testing.T) {
+	a := Suggestion{Concept: Concept{ID: "a"}}
+	b := Suggestion{Concept: Concept{ID: "b"}}
+	aAuthor := Suggestion{Concept: Concept{ID: "a"}, Predicate: predicateHasAuthor}
+
+	assert.Equal(t, []Suggestion{b, aAuthor}, difference([]Suggestion{a, b, aAuthor}, []Suggestion{a}))
+	assert.Equal(t, []Suggestion{a}, difference([]Suggestion{a, a}, []Suggestion{a}))
+	assert.Nil(t, difference([]Suggestion{a}, []Suggestion{a}))
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Financial-Times/go-logger/v2"
+)
+
+// ShadowOverlap compares the suggestions of a shadow Suggester with the live response for one concept type.
+type ShadowOverlap struct {
+	Shared     int `json:"shared"`
+	ShadowOnly int `json:"shadowOnly"`
+	LiveOnly   int `json:"liveOnly"`
+}
+
+type shadowResult struct {
+	suggestions []Suggestion
+	err         error
+}
+
+// shadowRun tracks the shadow Suggesters of one request.
+type shadowRun struct {
+	aggregate   *AggregateSuggester
+	ctx         context.Context
+	tid         string
+	origin      string
+	contentType string
+	// variant is the experiment variant of the request, its skipped filters are not applied to the shadows either.
+	variant *Variant
+	results []chan shadowResult
+}
+
+// startShadows calls the shadow Suggesters with the request payload, tid and origin.
+// They run on a context detached from the request cancellation and recording, so they can outlive the response
+// and never show in its timings or report.
+func (s *AggregateSuggester) startShadows(ctx context.Context, tid, origin string, content payloadContent, variant *Variant, payload []byte) *shadowRun {
+	ctx = ContextWithTimings(ContextWithReport(context.WithoutCancel(ctx), nil), nil)
+	run := &shadowRun{aggregate: s, ctx: ctx, tid: tid, origin: origin, contentType: content.contentType(), variant: variant}
+	for i, shadow := range s.Shadows {
+		result := make(chan shadowResult, 1)
+		run.results = append(run.results, result)
+		s.shadows.Add(1)
+		go func(i int, delegate Suggester) {
+			suggestions, err := getSuggestions(ctx, i, delegate, s.Concordance, noopObserver{}, tid, origin, payload)
+			result <- shadowResult{suggestions: suggestions, err: err}
+		}(i, shadow)
+	}
+	return run
+}
+
+// compare filters the shadow suggestions like the live ones, then logs and observes how they overlap with the live response.
+// It does not wait for the shadows.
+func (r *shadowRun) compare(live []Suggestion, blacklist Blacklist) {
+	for i, result := range r.results {
+		go func(delegate Suggester, result chan shadowResult) {
+			defer r.aggregate.shadows.Done()
+			logEntry := r.aggregate.Log.WithTransactionID(r.tid).WithField("shadow_suggester", delegate.GetName())
+
+			res := <-result
+			if res.err != nil {
+				logEntry.WithError(res.err).Warn("Shadow suggester failed")
+				return
+			}
+			suggestions := r.filter(logEntry, res.suggestions, blacklist)
+			overlap := overlapByConceptType(live, suggestions)
+			observer := r.aggregate.observer()
+			for conceptType, o := range overlap {
+				observer.ShadowCompared(delegate.GetName(), conceptType, o.Shared, o.ShadowOnly, o.LiveOnly)
+			}
+			logShadow(logEntry, suggestions, overlap)
+		}(r.aggregate.Shadows[i], result)
+	}
+}
+
+// filter applies the filters of the live suggestions: the broader concepts, the blacklist and the suppressed concepts,
+// which are moved last when they are demoted, except the filters the variant skips.
+func (r *shadowRun) filter(logEntry *logger.LogEntry, suggestions []Suggestion, blacklist Blacklist) []Suggestion {
+	if !r.variant.skips(FilterBroader) {
+		excluded, err := r.aggregate.BroaderProvider.excludeBroaderConceptsFromResponse(r.ctx, map[int][]Suggestion{0: suggestions}, r.tid)
+		if err != nil {
+			logEntry.WithError(err).Warn("Couldn't exclude broader concepts from the shadow suggestions")
+		} else {
+			suggestions = excluded[0]
+		}
+	}
+	if !r.variant.skips(FilterBlacklist) {
+		suggestions = filterDisallowedSuggestions(suggestions, blacklist, r.aggregate.Blacklister)
+	}
+	if r.aggregate.Suppressor != nil && !r.variant.skips(FilterSuppressed) {
+		kept, suppressed := splitSuppressed(r.aggregate.Suppressor, suggestions, r.origin, r.contentType)
+		suggestions = kept
+		if r.aggregate.DemoteSuppressed {
+			suggestions = append(suggestions, suppressed...)
+		}
+	}
+	return suggestions
+}
+
+// discard waits for the shadows in the background when the request failed and there is nothing to compare with.
+func (r *shadowRun) discard() {
+	for _, result := range r.results {
+		go func(result chan shadowResult) {
+			defer r.aggregate.shadows.Done()
+			<-result
+		}(result)
+	}
+}
+
+func logShadow(logEntry *logger.LogEntry, suggestions []Suggestion, overlap map[string]ShadowOverlap) {
+	ids := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		ids = append(ids, s.ID)
+	}
+	sort.Strings(ids)
+	logEntry.WithField("shadow_suggestions", ids).WithField("overlap", overlap).Info("Shadow suggestions compared with the live response")
+}
+
+// overlapByConceptType compares two lists of suggestions by concept and predicate.
+func overlapByConceptType(live, shadow []Suggestion) map[string]ShadowOverlap {
+	key := func(s Suggestion) string { return s.ID + " " + s.Predicate }
+	liveKeys := map[string]bool{}
+	for _, s := range live {
+		liveKeys[key(s)] = true
+	}
+	shadowKeys := map[string]bool{}
+	overlap := map[string]ShadowOverlap{}
+	for _, s := range shadow {
+		if shadowKeys[key(s)] {
+			continue
+		}
+		shadowKeys[key(s)] = true
+		o := overlap[ConceptTypeName(s)]
+		if liveKeys[key(s)] {
+			o.Shared++
+		} else {
+			o.ShadowOnly++
+		}
+		overlap[ConceptTypeName(s)] = o
+	}
+	for _, s := range live {
+		if shadowKeys[key(s)] {
+			continue
+		}
+		// count duplicates of the live response once
+		shadowKeys[key(s)] = true
+		o := overlap[ConceptTypeName(s)]
+		o.LiveOnly++
+		overlap[ConceptTypeName(s)] = o
+	}
+	return overlap
+}
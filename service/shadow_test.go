@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeShadowSuggester struct {
+	suggestions []Suggestion
+	err         error
+	ctxErr      chan error
+}
+
+func (s *fakeShadowSuggester) GetSuggestions(ctx context.Context, _ []byte, _, _ string) (SuggestionsResponse, error) {
+	s.ctxErr <- ctx.Err()
+	return SuggestionsResponse{Suggestions: s.suggestions}, s.err
+}
+
+func (s *fakeShadowSuggester) FilterSuggestions(suggestions []Suggestion) []Suggestion {
+	return suggestions
+}
+
+func (s *fakeShadowSuggester) GetName() string {
+	return "Shadow Suggestion API"
+}
+
+func TestAggregateSuggester_GetSuggestionsWithShadow(t *testing.T) {
+	person := Suggestion{Concept: Concept{ID: "person", PrefLabel: "Person", Type: ontologyPersonType}}
+	other := Suggestion{Concept: Concept{ID: "other", PrefLabel: "Other", Type: ontologyPersonType}}
+	topic := Suggestion{Concept: Concept{ID: "topic", PrefLabel: "Topic", Type: ontologyTopicType}}
+	vetoed := Suggestion{Concept: Concept{ID: "vetoed", PrefLabel: "Vetoed", Type: ontologyPersonType}}
+	concordance := NewConcordance("internalConcordancesHost", "/internalconcordances", newInternalConcordansesMock(t, "tid_test", map[string]Concept{
+		"person": person.Concept,
+		"other":  other.Concept,
+		"topic":  topic.Concept,
+		"vetoed": vetoed.Concept,
+	}))
+
+	suggestionApi := new(mockSuggestionApi)
+	suggestionApi.On("GetSuggestions", mock.AnythingOfType("[]uint8"), "tid_test", "tests_origin").Return(SuggestionsResponse{Suggestions: []Suggestion{person, topic}}, nil).Once()
+	suggestionApi.On("FilterSuggestions", []Suggestion{person, topic}).Return([]Suggestion{person, topic}).Once()
+	shadow := &fakeShadowSuggester{suggestions: []Suggestion{person, other, vetoed}, ctxErr: make(chan error, 1)}
+
+	publicThings := new(mockHttpClient)
+	publicThings.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"things":{}}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	blacklisterClient := new(mockHttpClient)
+	blacklisterClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":["vetoed"]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+
+	observer := &recordingObserver{returned: map[string]int{}, dropped: map[string]int{}}
+	aggregateSuggester := NewAggregateSuggester(logger.NewUPPLogger("test-service", "panic"),
+		concordance,
+		NewBroaderConceptsProvider("publicThingsUrl", "/things", publicThings),
+		NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterClient),
+		suggestionApi)
+	aggregateSuggester.Observer = observer
+	aggregateSuggester.Shadows = []Suggester{shadow}
+
+	report := &Report{}
+	ctx, cancel := context.WithCancel(ContextWithReport(context.Background(), report))
+	response, err := aggregateSuggester.GetSuggestions(ctx, []byte("{}"), "tid_test", "tests_origin")
+	cancel()
+	require.NoError(t, err)
+	aggregateSuggester.WaitForShadows()
+
+	assert.Equal(t, []Suggestion{person, topic}, response.Suggestions)
+	assert.NoError(t, <-shadow.ctxErr)
+	assert.Len(t, report.Sources(), 1, "the shadows are not in the request report")
+	assert.Equal(t, map[string]int{"Mock Suggestion API/Person": 1, "Mock Suggestion API/Topic": 1}, observer.returned)
+	assert.ElementsMatch(t, []string{
+		"Shadow Suggestion API/Person shared=1 shadowOnly=1 liveOnly=0",
+		"Shadow Suggestion API/Topic shared=0 shadowOnly=0 liveOnly=1",
+	}, observer.shadows)
+	suggestionApi.AssertExpectations(t)
+}
+
+func TestAggregateSuggester_GetSuggestionsWithSuppressedShadow(t *testing.T) {
+	person := Suggestion{Concept: Concept{ID: "person", PrefLabel: "Person", Type: ontologyPersonType}}
+	other := Suggestion{Concept: Concept{ID: "other", PrefLabel: "Other", Type: ontologyPersonType}}
+	concordance := NewConcordance("internalConcordancesHost", "/internalconcordances", newInternalConcordansesMock(t, "tid_test", map[string]Concept{
+		"person": person.Concept,
+		"other":  other.Concept,
+	}))
+
+	suggestionApi := new(mockSuggestionApi)
+	suggestionApi.On("GetSuggestions", mock.AnythingOfType("[]uint8"), "tid_test", "tests_origin").Return(SuggestionsResponse{Suggestions: []Suggestion{person, other}}, nil).Once()
+	suggestionApi.On("FilterSuggestions", []Suggestion{person, other}).Return([]Suggestion{person, other}).Once()
+	shadow := &fakeShadowSuggester{suggestions: []Suggestion{person, other}, ctxErr: make(chan error, 1)}
+
+	publicThings := new(mockHttpClient)
+	publicThings.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"things":{}}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	blacklisterClient := new(mockHttpClient)
+	blacklisterClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":[]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+
+	observer := &recordingObserver{returned: map[string]int{}, dropped: map[string]int{}}
+	aggregateSuggester := NewAggregateSuggester(logger.NewUPPLogger("test-service", "panic"),
+		concordance,
+		NewBroaderConceptsProvider("publicThingsUrl", "/things", publicThings),
+		NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterClient),
+		suggestionApi)
+	aggregateSuggester.Observer = observer
+	aggregateSuggester.Shadows = []Suggester{shadow}
+	aggregateSuggester.Suppressor = staticSuppressor{"other": "Article"}
+
+	response, err := aggregateSuggester.GetSuggestions(context.Background(), []byte(`{"type":"http://www.ft.com/ontology/content/Article"}`), "tid_test", "tests_origin")
+	require.NoError(t, err)
+	aggregateSuggester.WaitForShadows()
+
+	assert.Equal(t, []Suggestion{person}, response.Suggestions)
+	assert.Equal(t, []string{"Shadow Suggestion API/Person shared=1 shadowOnly=0 liveOnly=0"}, observer.shadows,
+		"the suppressed concept is filtered out of the shadow suggestions as of the live ones")
+	suggestionApi.AssertExpectations(t)
+}
+
+func TestOverlapByConceptType(t *testing.T) {
+	person := Suggestion{Concept: Concept{ID: "person", Type: ontologyPersonType}}
+	author := Suggestion{Concept: Concept{ID: "person", Type: ontologyPersonType}, Predicate: predicateHasAuthor}
+	location := Suggestion{Concept: Concept{ID: "location", Type: ontologyLocationType}}
+	topic := Suggestion{Concept: Concept{ID: "topic", Type: ontologyTopicType}}
+
+	assert.Equal(t, map[string]ShadowOverlap{
+		"Person":   {Shared: 1, ShadowOnly: 1},
+		"Location": {LiveOnly: 1},
+		"Topic":    {Shared: 1},
+	}, overlapByConceptType([]Suggestion{person, location, topic, topic}, []Suggestion{person, author, topic, topic}))
+	assert.Empty(t, overlapByConceptType(nil, nil))
+}
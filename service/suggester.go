@@ -2,13 +2,17 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 
 	health "github.com/Financial-Times/go-fthealth/v1_1"
+	"github.com/Financial-Times/public-suggestions-api/content"
 	"github.com/Financial-Times/public-suggestions-api/reqorigin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -83,11 +87,16 @@ type Client interface {
 }
 
 type Suggester interface {
-	GetSuggestions(payload []byte, tid, origin string) (SuggestionsResponse, error)
+	GetSuggestions(ctx context.Context, payload []byte, tid, origin string) (SuggestionsResponse, error)
 	FilterSuggestions(suggestions []Suggestion) []Suggestion
 	GetName() string
 }
 
+// PayloadProjection builds the payload a suggester receives from the content payload.
+type PayloadProjection interface {
+	Project(payload []byte) ([]byte, error)
+}
+
 type SuggestionApi struct {
 	name                 string
 	targetedConceptTypes []string
@@ -96,6 +105,8 @@ type SuggestionApi struct {
 	client               Client
 	systemId             string
 	failureImpact        string
+	// Projection is optional, when set the suggester receives the projected payload instead of the whole one.
+	Projection PayloadProjection
 }
 
 type AuthorsSuggester struct {
@@ -109,6 +120,8 @@ type OntotextSuggester struct {
 type Suggestion struct {
 	Concept
 	Predicate string `json:"predicate,omitempty"`
+	// Status is only set when the request lists its existing annotations, see AnnotationNew.
+	Status string `json:"status,omitempty"`
 }
 
 type Concept struct {
@@ -121,6 +134,12 @@ type Concept struct {
 
 type SuggestionsResponse struct {
 	Suggestions []Suggestion `json:"suggestions"`
+	// Timings is only filled in when the client asks for the stage breakdown in the response body.
+	Timings []StageTiming `json:"timings,omitempty"`
+	// ExistingAnnotations is only set when the request lists its existing annotations.
+	ExistingAnnotations []ExistingAnnotation `json:"existingAnnotations,omitempty"`
+	// Transformations are the changes made to the body fields of the payload before it was sent to the suggesters.
+	Transformations []content.Transformation `json:"transformations,omitempty"`
 }
 
 func NewAuthorsSuggester(authorsSuggestionApiBaseURL, authorsSuggestionEndpoint string, client Client) *AuthorsSuggester {
@@ -147,8 +166,40 @@ func NewOntotextSuggester(ontotextSuggestionApiBaseURL, ontotextSuggestionEndpoi
 	}}
 }
 
-func (suggester *SuggestionApi) GetSuggestions(payload []byte, tid, origin string) (SuggestionsResponse, error) {
-	req, err := http.NewRequest("POST", suggester.apiBaseURL+suggester.suggestionEndpoint, bytes.NewReader(payload))
+// NewShadowSuggester creates a Suggester for a suggestion API on trial, it keeps the suggestions of every concept type
+// so they can all be compared with the response.
+func NewShadowSuggester(name, baseURL, endpoint string, client Client) *SuggestionApi {
+	return &SuggestionApi{
+		apiBaseURL:           baseURL,
+		suggestionEndpoint:   endpoint,
+		client:               client,
+		name:                 name,
+		targetedConceptTypes: []string{PseudoConceptTypeAuthor, LocationSourceParam, OrganisationSourceParam, PersonSourceParam, TopicSourceParam},
+		systemId:             name,
+		failureImpact:        "Shadow suggestions of " + name + " won't be compared with the response, the response is not affected",
+	}
+}
+
+func (suggester *SuggestionApi) GetSuggestions(ctx context.Context, payload []byte, tid, origin string) (SuggestionsResponse, error) {
+	ctx, span := tracer().Start(ctx, "Suggester.GetSuggestions", trace.WithAttributes(attribute.String("suggester.name", suggester.name)))
+	defer span.End()
+
+	response, err := suggester.getSuggestions(ctx, payload, tid, origin)
+	recordSpanError(span, err)
+	return response, err
+}
+
+func (suggester *SuggestionApi) getSuggestions(ctx context.Context, payload []byte, tid, origin string) (SuggestionsResponse, error) {
+	if suggester.Projection != nil {
+		projected, err := suggester.Projection.Project(payload)
+		if err != nil {
+			return SuggestionsResponse{}, &SuggesterErr{err: err}
+		}
+		payload = projected
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("suggester.payload.bytes", len(payload)))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", suggester.apiBaseURL+suggester.suggestionEndpoint, bytes.NewReader(payload))
 	if err != nil {
 		return SuggestionsResponse{}, &SuggesterErr{err: err}
 	}
@@ -172,10 +223,10 @@ func (suggester *SuggestionApi) GetSuggestions(payload []byte, tid, origin strin
 
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusNoContent {
-			return SuggestionsResponse{make([]Suggestion, 0)}, NoContentError
+			return SuggestionsResponse{Suggestions: make([]Suggestion, 0)}, NoContentError
 		}
 		if resp.StatusCode == http.StatusBadRequest {
-			return SuggestionsResponse{make([]Suggestion, 0)}, BadRequestError
+			return SuggestionsResponse{Suggestions: make([]Suggestion, 0)}, BadRequestError
 		}
 		return SuggestionsResponse{}, &SuggesterErr{msg: fmt.Sprintf("%v returned HTTP %v", suggester.name, resp.StatusCode)}
 	}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -54,7 +55,7 @@ func (m *mockSuggestionApi) FilterSuggestions(suggestions []Suggestion) []Sugges
 	return args.Get(0).([]Suggestion)
 }
 
-func (m *mockSuggestionApi) GetSuggestions(payload []byte, tid, origin string) (SuggestionsResponse, error) {
+func (m *mockSuggestionApi) GetSuggestions(ctx context.Context, payload []byte, tid, origin string) (SuggestionsResponse, error) {
 	args := m.Called(payload, tid, origin)
 	return args.Get(0).(SuggestionsResponse), args.Error(1)
 }
@@ -166,7 +167,7 @@ func TestOntotextSuggester_GetSuggestionsSuccessfullyWithoutAuthors(t *testing.T
 	defer server.Close()
 
 	suggester := NewOntotextSuggester(server.URL, "/content/suggest", http.DefaultClient)
-	suggestionResp, err := suggester.GetSuggestions(body, "tid_test", "tests_origin")
+	suggestionResp, err := suggester.GetSuggestions(context.Background(), body, "tid_test", "tests_origin")
 	suggestionResp.Suggestions = suggester.FilterSuggestions(suggestionResp.Suggestions)
 
 	actualSuggestions := suggestionResp.Suggestions
@@ -178,6 +179,40 @@ func TestOntotextSuggester_GetSuggestionsSuccessfullyWithoutAuthors(t *testing.T
 	mock.AssertExpectationsForObjects(t, mockServer)
 }
 
+// projectionFunc projects the payloads with a function.
+type projectionFunc func(payload []byte) ([]byte, error)
+
+func (f projectionFunc) Project(payload []byte) ([]byte, error) {
+	return f(payload)
+}
+
+func TestSuggestionApi_GetSuggestionsWithProjection(t *testing.T) {
+	mockServer := new(mockSuggestionApiServer)
+	mockServer.On("UploadRequest", []byte(`{"byline":"byline"}`), "tid_test", "application/json", "application/json").Return(http.StatusOK, []byte(sampleJSONResponse))
+	server := mockServer.startMockServer(t)
+	defer server.Close()
+
+	suggester := NewAuthorsSuggester(server.URL, "/content/suggest", http.DefaultClient)
+	suggester.Projection = projectionFunc(func(payload []byte) ([]byte, error) {
+		return []byte(`{"byline":"byline"}`), nil
+	})
+	resp, err := suggester.GetSuggestions(context.Background(), []byte(`{"byline":"byline","title":"title"}`), "tid_test", "tests_origin")
+	assert.NoError(t, err)
+	assert.Len(t, resp.Suggestions, 2)
+	mock.AssertExpectationsForObjects(t, mockServer)
+}
+
+func TestSuggestionApi_GetSuggestionsProjectionError(t *testing.T) {
+	suggester := NewAuthorsSuggester("http://authors", "/content/suggest", http.DefaultClient)
+	suggester.Projection = projectionFunc(func(payload []byte) ([]byte, error) {
+		return nil, errors.New("projection failed")
+	})
+	_, err := suggester.GetSuggestions(context.Background(), []byte(`{"byline":"byline"}`), "tid_test", "tests_origin")
+	var suggesterErr *SuggesterErr
+	assert.True(t, errors.As(err, &suggesterErr))
+	assert.EqualError(t, err, "projection failed")
+}
+
 func TestErrors(t *testing.T) {
 	err := fmt.Errorf("non 200 status code returned: %d", 400)
 	var target *SuggesterErr
@@ -256,7 +291,7 @@ func TestOntotextSuggester_GetSuggestionsWithServiceUnavailable(t *testing.T) {
 	defer server.Close()
 
 	suggester := NewOntotextSuggester(server.URL, "/content/suggest", http.DefaultClient)
-	suggestionResp, err := suggester.GetSuggestions([]byte("{}"), "tid_test", "tests_origin")
+	suggestionResp, err := suggester.GetSuggestions(context.Background(), []byte("{}"), "tid_test", "tests_origin")
 	var sErr *SuggesterErr
 	expect.True(errors.As(err, &sErr))
 	expect.Equal("Ontotext Suggestion API returned HTTP 503", sErr.Error())
@@ -268,7 +303,7 @@ func TestOntotextSuggester_GetSuggestionsWithServiceUnavailable(t *testing.T) {
 func TestOntotextSuggester_GetSuggestionsErrorOnNewRequest(t *testing.T) {
 	expect := assert.New(t)
 	suggester := NewOntotextSuggester(":/", "/content/suggest", http.DefaultClient)
-	suggestionResp, err := suggester.GetSuggestions([]byte("{}"), "tid_test", "tests_origin")
+	suggestionResp, err := suggester.GetSuggestions(context.Background(), []byte("{}"), "tid_test", "tests_origin")
 
 	expect.Nil(suggestionResp.Suggestions)
 	var urlErr *url.Error
@@ -283,7 +318,7 @@ func TestOntotextSuggester_GetSuggestionsErrorOnRequestDo(t *testing.T) {
 	mockClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{}, errors.New("Http Client err"))
 
 	suggester := NewOntotextSuggester("http://test-url", "/content/suggest", mockClient)
-	suggestionResp, err := suggester.GetSuggestions([]byte("{}"), "tid_test", "tests_origin")
+	suggestionResp, err := suggester.GetSuggestions(context.Background(), []byte("{}"), "tid_test", "tests_origin")
 
 	expect.Nil(suggestionResp.Suggestions)
 	var sErr *SuggesterErr
@@ -302,7 +337,7 @@ func TestOntotextSuggester_GetSuggestionsErrorOnResponseBodyRead(t *testing.T) {
 	mockBody.On("Close").Return(nil)
 
 	suggester := NewOntotextSuggester("http://test-url", "/content/suggest", mockClient)
-	suggestionResp, err := suggester.GetSuggestions([]byte("{}"), "tid_test", "tests_origin")
+	suggestionResp, err := suggester.GetSuggestions(context.Background(), []byte("{}"), "tid_test", "tests_origin")
 
 	expect.Nil(suggestionResp.Suggestions)
 	var sErr *SuggesterErr
@@ -320,7 +355,7 @@ func TestOntotextSuggester_GetSuggestionsErrorOnEmptyBodyResponse(t *testing.T)
 	defer server.Close()
 
 	suggester := NewOntotextSuggester(server.URL, "/content/suggest", http.DefaultClient)
-	suggestionResp, err := suggester.GetSuggestions([]byte("{}"), "tid_test", "tests_origin")
+	suggestionResp, err := suggester.GetSuggestions(context.Background(), []byte("{}"), "tid_test", "tests_origin")
 
 	var sErr *SuggesterErr
 	expect.True(errors.As(err, &sErr))
@@ -429,7 +464,7 @@ func TestAuthorsSuggester_GetSuggestionsSuccessfully(t *testing.T) {
 	defer server.Close()
 
 	suggester := NewAuthorsSuggester(server.URL, "/content/suggest", http.DefaultClient)
-	suggestionResp, err := suggester.GetSuggestions(body, "tid_test", "tests_origin")
+	suggestionResp, err := suggester.GetSuggestions(context.Background(), body, "tid_test", "tests_origin")
 
 	actualSuggestions := suggestionResp.Suggestions
 	expect.NoError(err)
@@ -449,7 +484,7 @@ func TestOntotext_ErrorFromService(t *testing.T) {
 	ontotextHTTPMock.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{}, fmt.Errorf("Error from ontotext-suggestion-api"))
 
 	suggester := NewOntotextSuggester("ontotextURL", "ontotextEndpoint", ontotextHTTPMock)
-	resp, err := suggester.GetSuggestions([]byte("{}"), "tid_test", "tests_origin")
+	resp, err := suggester.GetSuggestions(context.Background(), []byte("{}"), "tid_test", "tests_origin")
 
 	var sErr *SuggesterErr
 	expect.True(errors.As(err, &sErr))
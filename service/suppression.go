@@ -0,0 +1,20 @@
+package service
+
+// Suppressor tells which concepts should not be suggested for the origin and the content type of a request,
+// e.g. because the editors keep rejecting them. contentType is the short name of the content type, e.g. "Article".
+type Suppressor interface {
+	IsSuppressed(conceptID, origin, contentType string) bool
+}
+
+// splitSuppressed separates the suppressed suggestions, keeping the order of both.
+func splitSuppressed(suppressor Suppressor, suggestions []Suggestion, origin, contentType string) (kept, suppressed []Suggestion) {
+	kept = []Suggestion{}
+	for _, s := range suggestions {
+		if suppressor.IsSuppressed(s.ID, origin, contentType) {
+			suppressed = append(suppressed, s)
+		} else {
+			kept = append(kept, s)
+		}
+	}
+	return kept, suppressed
+}
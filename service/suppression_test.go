@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// staticSuppressor suppresses the concept UUIDs for an origin or a content type.
+type staticSuppressor map[string]string
+
+func (s staticSuppressor) IsSuppressed(conceptID, origin, contentType string) bool {
+	scope, ok := s[conceptID]
+	return ok && (scope == origin || scope == contentType)
+}
+
+func TestAggregateSuggester_GetSuggestionsWithSuppressor(t *testing.T) {
+	person := Suggestion{Concept: Concept{ID: "person", PrefLabel: "Person", Type: ontologyPersonType}}
+	topic := Suggestion{Concept: Concept{ID: "topic", PrefLabel: "Topic", Type: ontologyTopicType}}
+	location := Suggestion{Concept: Concept{ID: "location", PrefLabel: "Location", Type: ontologyLocationType}}
+	suppressor := staticSuppressor{"person": "spark", "topic": "Article"}
+
+	testCases := []struct {
+		name     string
+		origin   string
+		payload  string
+		demote   bool
+		expected []Suggestion
+		dropped  []DroppedSuggestion
+	}{
+		{
+			name:     "suppressed for the origin",
+			origin:   "spark",
+			payload:  `{"id":"content"}`,
+			expected: []Suggestion{topic, location},
+			dropped:  []DroppedSuggestion{{Suggestion: person, Source: "Shadow Suggestion API", Filter: FilterSuppressed}},
+		},
+		{
+			name:     "suppressed for the content type",
+			origin:   "other",
+			payload:  `{"id":"content","type":"http://www.ft.com/ontology/content/Article"}`,
+			expected: []Suggestion{person, location},
+			dropped:  []DroppedSuggestion{{Suggestion: topic, Source: "Shadow Suggestion API", Filter: FilterSuppressed}},
+		},
+		{
+			name:     "demoted",
+			origin:   "spark",
+			payload:  `{"id":"content","type":"http://www.ft.com/ontology/content/Article"}`,
+			demote:   true,
+			expected: []Suggestion{location, person, topic},
+			dropped:  []DroppedSuggestion{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			concordance := NewConcordance("internalConcordancesHost", "/internalconcordances", newInternalConcordansesMock(t, "tid_test", map[string]Concept{
+				"person":   person.Concept,
+				"topic":    topic.Concept,
+				"location": location.Concept,
+			}))
+			suggester := &fakeShadowSuggester{suggestions: []Suggestion{person, topic, location}, ctxErr: make(chan error, 1)}
+			publicThings := new(mockHttpClient)
+			publicThings.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader(`{"things":{}}`)),
+				StatusCode: http.StatusOK,
+			}, nil)
+			blacklisterClient := new(mockHttpClient)
+			blacklisterClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":[]}`)),
+				StatusCode: http.StatusOK,
+			}, nil)
+
+			aggregateSuggester := NewAggregateSuggester(logger.NewUPPLogger("test-service", "panic"),
+				concordance,
+				NewBroaderConceptsProvider("publicThingsUrl", "/things", publicThings),
+				NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterClient),
+				suggester)
+			aggregateSuggester.Suppressor = suppressor
+			aggregateSuggester.DemoteSuppressed = tc.demote
+
+			report := &Report{}
+			response, err := aggregateSuggester.GetSuggestions(ContextWithReport(context.Background(), report), []byte(tc.payload), "tid_test", tc.origin)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, response.Suggestions)
+			assert.Equal(t, tc.dropped, report.Dropped())
+		})
+	}
+}
+
+func TestSplitSuppressed(t *testing.T) {
+	a := Suggestion{Concept: Concept{ID: "a"}}
+	b := Suggestion{Concept: Concept{ID: "b"}}
+	c := Suggestion{Concept: Concept{ID: "c"}}
+	kept, suppressed := splitSuppressed(staticSuppressor{"b": "spark"}, []Suggestion{a, b, c}, "spark", "")
+	assert.Equal(t, []Suggestion{a, c}, kept)
+	assert.Equal(t, []Suggestion{b}, suppressed)
+}
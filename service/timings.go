@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	StageTotal       = "total"
+	StageBroader     = "broader"
+	StageBlacklist   = "blacklist"
+	stageSuggest     = "suggest."
+	stageConcordance = "concordance."
+)
+
+// StageTiming is the duration of one stage of the suggestions aggregation.
+type StageTiming struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Duration    time.Duration `json:"-"`
+	DurationMs  float64       `json:"durationMs"`
+	start       time.Time
+}
+
+// Timings collects the StageTiming of a request. It is safe for concurrent use.
+type Timings struct {
+	mu     sync.Mutex
+	stages []StageTiming
+}
+
+type timingsKey struct{}
+
+// ContextWithTimings returns a context that makes the AggregateSuggester record its stage durations into t.
+func ContextWithTimings(ctx context.Context, t *Timings) context.Context {
+	return context.WithValue(ctx, timingsKey{}, t)
+}
+
+func timingsFromContext(ctx context.Context) *Timings {
+	t, _ := ctx.Value(timingsKey{}).(*Timings)
+	return t
+}
+
+// Stages returns the recorded timings ordered by their start time.
+func (t *Timings) Stages() []StageTiming {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stages := make([]StageTiming, len(t.stages))
+	copy(stages, t.stages)
+	sort.SliceStable(stages, func(i, j int) bool {
+		return stages[i].start.Before(stages[j].start)
+	})
+	return stages
+}
+
+// start begins measuring a stage and returns the function that ends it.
+// It is a no-op on a nil *Timings, so callers do not need to check if timings were requested.
+func (t *Timings) start(name, description string) func() {
+	if t == nil {
+		return func() {}
+	}
+	begin := time.Now()
+	return func() {
+		elapsed := time.Since(begin)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.stages = append(t.stages, StageTiming{
+			Name:        name,
+			Description: description,
+			Duration:    elapsed,
+			DurationMs:  float64(elapsed.Microseconds()) / 1000,
+			start:       begin,
+		})
+	}
+}
+
+// stageID turns a suggester name into a token usable as a stage name, e.g. "Authors Suggestion API" becomes "authors-suggestion-api".
+func stageID(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), "-"))
+}
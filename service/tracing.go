@@ -0,0 +1,23 @@
+package service
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Financial-Times/public-suggestions-api/service"
+
+// tracer uses the global TracerProvider, which is a no-op until tracing is configured. It is looked up on every span
+// so that a TracerProvider set later, e.g. by a test, is used.
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+func recordSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
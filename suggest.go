@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	cli "github.com/jawher/mow.cli"
+)
+
+const (
+	suggestDescription = "Suggest annotations for content files without starting the server. The downstream services are configured by the application options"
+	suggestOrigin      = "public-suggestions-api-suggest"
+)
+
+// The exit statuses of the suggest subcommand.
+const (
+	suggestOK             = 0
+	suggestFailed         = 1
+	suggestPartialFailure = 2
+)
+
+// offlineSuggester is implemented by service.AggregateSuggester.
+type offlineSuggester interface {
+	GetSuggestions(ctx context.Context, payload []byte, tid, origin string) (service.SuggestionsResponse, error)
+}
+
+func suggestCommand(cmd *cli.Cmd, log *logger.UPPLogger, newSuggester func() offlineSuggester) {
+	cmd.Spec = "[--format] [--explain] [--sources] [FILES...]"
+
+	format := cmd.String(cli.StringOpt{
+		Name:  "format",
+		Value: "json",
+		Desc:  "Output format: json or table",
+	})
+	explain := cmd.Bool(cli.BoolOpt{
+		Name: "explain",
+		Desc: "Show the dropped suggestions with the filter that dropped them, the stage timings and the experiment variant",
+	})
+	sources := cmd.Bool(cli.BoolOpt{
+		Name: "sources",
+		Desc: "Show the raw and returned suggestions of every suggester",
+	})
+	files := cmd.Strings(cli.StringsArg{
+		Name: "FILES",
+		Desc: "Content JSON files, stdin when none or -",
+	})
+
+	cmd.Action = func() {
+		// keep stdout for the suggestions
+		log.Out = os.Stderr
+		status, err := runSuggest(newSuggester(), *files, *format, *explain, *sources, os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "suggest failed: %v\n", err)
+		}
+		cli.Exit(status)
+	}
+}
+
+// suggestOutput is what the suggest subcommand prints for every file.
+type suggestOutput struct {
+	File string `json:"file"`
+	service.SuggestionsResponse
+	Experiment string                      `json:"experiment,omitempty"`
+	Variant    string                      `json:"variant,omitempty"`
+	Dropped    []service.DroppedSuggestion `json:"dropped,omitempty"`
+	Sources    []service.SourceReport      `json:"sources,omitempty"`
+	Error      string                      `json:"error,omitempty"`
+	// partial is set when some suggesters failed
+	partial bool
+}
+
+// runSuggest prints the suggestions of every file and returns the exit status: suggestFailed when a file could not
+// be suggested, suggestPartialFailure when a suggester failed for some file, suggestOK otherwise.
+func runSuggest(suggester offlineSuggester, files []string, format string, explain, sources bool, out io.Writer) (int, error) {
+	if format != "json" && format != "table" {
+		return suggestFailed, fmt.Errorf("unknown output format %q", format)
+	}
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	status := suggestOK
+	outputs := make([]suggestOutput, 0, len(files))
+	for _, file := range files {
+		output := suggestFile(suggester, file, explain, sources)
+		switch {
+		case output.Error != "":
+			status = suggestFailed
+		case output.partial && status == suggestOK:
+			status = suggestPartialFailure
+		}
+		outputs = append(outputs, output)
+	}
+
+	var err error
+	if format == "json" {
+		err = writeSuggestJSON(out, outputs)
+	} else {
+		err = writeSuggestTable(out, outputs)
+	}
+	if err != nil {
+		return suggestFailed, err
+	}
+	return status, nil
+}
+
+func suggestFile(suggester offlineSuggester, file string, explain, sources bool) suggestOutput {
+	output := suggestOutput{File: file}
+	var payload []byte
+	var err error
+	if file == "-" {
+		payload, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		payload, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		output.Error = err.Error()
+		return output
+	}
+	var content map[string]interface{}
+	if err := json.Unmarshal(payload, &content); err != nil || len(content) == 0 {
+		output.Error = "content should be a non-empty JSON object"
+		return output
+	}
+
+	timings := &service.Timings{}
+	report := &service.Report{}
+	ctx := service.ContextWithTimings(context.Background(), timings)
+	ctx = service.ContextWithReport(ctx, report)
+	response, err := suggester.GetSuggestions(ctx, payload, tidutils.NewTransactionID(), suggestOrigin)
+	if err != nil {
+		output.Error = err.Error()
+		return output
+	}
+	output.SuggestionsResponse = response
+	for _, source := range report.Sources() {
+		output.partial = output.partial || source.Failed()
+	}
+	if explain {
+		output.Timings = timings.Stages()
+		output.Experiment, output.Variant = report.Variant()
+		output.Dropped = report.Dropped()
+	}
+	if sources {
+		output.Sources = report.Sources()
+	}
+	return output
+}
+
+func writeSuggestJSON(w io.Writer, outputs []suggestOutput) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	for _, output := range outputs {
+		if err := enc.Encode(output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSuggestTable(w io.Writer, outputs []suggestOutput) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i, output := range outputs {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "== %s\n", output.File)
+		if output.Error != "" {
+			fmt.Fprintf(tw, "error: %s\n", output.Error)
+			continue
+		}
+		if output.Experiment != "" {
+			fmt.Fprintf(tw, "experiment %s, variant %s\n", output.Experiment, output.Variant)
+		}
+		fmt.Fprintln(tw, "PREFLABEL\tTYPE\tPREDICATE\tID\tSTATUS")
+		for _, s := range output.Suggestions {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.PrefLabel, service.ConceptTypeName(s), shortPredicate(s.Predicate), s.ID, s.Status)
+		}
+		if len(output.Dropped) > 0 {
+			fmt.Fprintln(tw, "\nDROPPED\tSOURCE\tFILTER\tID")
+			for _, d := range output.Dropped {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.PrefLabel, d.Source, d.Filter, d.ID)
+			}
+		}
+		if len(output.Sources) > 0 {
+			fmt.Fprintln(tw, "\nSOURCE\tRAW\tRETURNED\tERROR")
+			for _, s := range output.Sources {
+				fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", s.Source, len(s.Suggestions), len(s.Returned), s.Error)
+			}
+		}
+		if len(output.Timings) > 0 {
+			fmt.Fprintln(tw, "\nSTAGE\tMS")
+			for _, stage := range output.Timings {
+				fmt.Fprintf(tw, "%s\t%.1f\n", stage.Name, stage.DurationMs)
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+// shortPredicate returns the last segment of the predicate URI, e.g. mentions.
+func shortPredicate(predicate string) string {
+	return predicate[strings.LastIndex(predicate, "/")+1:]
+}
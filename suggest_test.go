@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDownstreamSuggester aggregates an ontotext suggester suggesting London and an authors suggester
+// that fails when authorsFail is set.
+func newDownstreamSuggester(t *testing.T, authorsFail bool) *service.AggregateSuggester {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ontotext":
+			w.Write([]byte(`{"suggestions":[{"id":"http://www.ft.com/thing/london","predicate":"http://www.ft.com/ontology/annotation/mentions"}]}`))
+		case "/authors":
+			if authorsFail {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"suggestions":[]}`))
+		case "/internalconcordances":
+			w.Write([]byte(`{"concepts":{"london":{"id":"http://www.ft.com/thing/london","prefLabel":"London","type":"http://www.ft.com/ontology/Location"}}}`))
+		case "/things":
+			w.Write([]byte(`{"things":{}}`))
+		case "/blacklist":
+			w.Write([]byte(`{"uuids":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return service.NewAggregateSuggester(logger.NewUPPLogger("test", "panic"),
+		service.NewConcordance(server.URL, "/internalconcordances", server.Client()),
+		service.NewBroaderConceptsProvider(server.URL, "/things", server.Client()),
+		service.NewConceptBlacklister(server.URL, "/blacklist", server.Client()),
+		service.NewAuthorsSuggester(server.URL, "/authors", server.Client()),
+		service.NewOntotextSuggester(server.URL, "/ontotext", server.Client()))
+}
+
+func writeContent(t *testing.T, dir, name, content string) string {
+	file := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func TestRunSuggest(t *testing.T) {
+	dir := t.TempDir()
+	article := writeContent(t, dir, "article.json", `{"id":"http://www.ft.com/thing/f758ef56-c40a-3162-91aa-3e8a3aabc495","bodyXML":"London"}`)
+
+	out := &bytes.Buffer{}
+	status, err := runSuggest(newDownstreamSuggester(t, false), []string{article}, "json", false, false, out)
+	require.NoError(t, err)
+	assert.Equal(t, suggestOK, status)
+	var output map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &output))
+	assert.Equal(t, article, output["file"])
+	assert.Len(t, output["suggestions"], 1)
+	assert.NotContains(t, output, "sources")
+	assert.NotContains(t, output, "timings")
+
+	out.Reset()
+	status, err = runSuggest(newDownstreamSuggester(t, false), []string{article}, "json", true, true, out)
+	require.NoError(t, err)
+	assert.Equal(t, suggestOK, status)
+	output = nil
+	require.NoError(t, json.Unmarshal(out.Bytes(), &output))
+	assert.Len(t, output["sources"], 2)
+	assert.NotEmpty(t, output["timings"])
+}
+
+func TestRunSuggest_PartialFailure(t *testing.T) {
+	article := writeContent(t, t.TempDir(), "article.json", `{"bodyXML":"London"}`)
+
+	out := &bytes.Buffer{}
+	status, err := runSuggest(newDownstreamSuggester(t, true), []string{article}, "table", false, true, out)
+	require.NoError(t, err)
+	assert.Equal(t, suggestPartialFailure, status, "the authors suggester failed")
+	assert.Contains(t, out.String(), "London     Location  mentions   http://www.ft.com/thing/london")
+	assert.Contains(t, out.String(), "Authors Suggestion API   0    0         Authors Suggestion API returned HTTP 503")
+}
+
+func TestRunSuggest_Failure(t *testing.T) {
+	dir := t.TempDir()
+	article := writeContent(t, dir, "article.json", `{"bodyXML":"London"}`)
+	empty := writeContent(t, dir, "empty.json", `{}`)
+	missing := filepath.Join(dir, "missing.json")
+
+	out := &bytes.Buffer{}
+	status, err := runSuggest(newDownstreamSuggester(t, true), []string{article, empty, missing}, "table", false, false, out)
+	require.NoError(t, err)
+	assert.Equal(t, suggestFailed, status)
+	assert.Contains(t, out.String(), "== "+empty+"\nerror: content should be a non-empty JSON object")
+	assert.Contains(t, out.String(), "== "+missing+"\nerror: ")
+
+	_, err = runSuggest(newDownstreamSuggester(t, false), []string{article}, "csv", false, false, out)
+	assert.EqualError(t, err, `unknown output format "csv"`)
+}
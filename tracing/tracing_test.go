@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+// restoreGlobals puts back the global TracerProvider and propagator Setup replaces once the test is over.
+func restoreGlobals(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+}
+
+func TestSetup_FileExporterAndPropagation(t *testing.T) {
+	restoreGlobals(t)
+	path := filepath.Join(t.TempDir(), "spans.json")
+	shutdown, err := Setup(context.Background(), Config{
+		ServiceName: "public-suggestions-api",
+		Exporter:    ExporterFile,
+		FilePath:    path,
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+
+	var downstreamTraceparent string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstreamTraceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer downstream.Close()
+	client := InstrumentClient(&http.Client{})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/content/{uuid}/suggest", func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, downstream.URL, nil)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	const callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/content/0ba9b1b8-0f05-11e9-9e6d-5bcb4a4d2e0a/suggest", nil)
+	req.Header.Set("traceparent", "00-"+callerTraceID+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	Handler(router, router).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, downstreamTraceparent, callerTraceID, "the caller trace should be propagated downstream")
+
+	require.NoError(t, shutdown(context.Background()))
+	spans, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(spans), `"Name":"GET /content/{uuid}/suggest"`)
+	assert.Contains(t, string(spans), callerTraceID)
+}
+
+func TestSetup_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"unknown exporter", Config{Exporter: "zipkin"}},
+		{"file without path", Config{Exporter: ExporterFile}},
+		{"invalid ratio", Config{Exporter: ExporterStdout, SampleRatio: 2}},
+		{"invalid ratio without exporter", Config{Exporter: ExporterNone, SampleRatio: -1}},
+	}
+	restoreGlobals(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Setup(context.Background(), test.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSetup_None(t *testing.T) {
+	restoreGlobals(t)
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
@@ -0,0 +1,70 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+const APIPath = "/__api"
+
+// APIHandler serves the OpenAPI specification of the service.
+type APIHandler struct {
+	spec []byte
+}
+
+// NewAPIHandler serves the YAML spec, with the version of its info set to version unless it is empty.
+func NewAPIHandler(spec []byte, version string) (*APIHandler, error) {
+	if version == "" {
+		return &APIHandler{spec: spec}, nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(spec, &document); err != nil {
+		return nil, fmt.Errorf("invalid API spec: %w", err)
+	}
+	info := mappingValue(&document, "info")
+	if info == nil {
+		return nil, fmt.Errorf("invalid API spec: no info")
+	}
+	v := mappingValue(info, "version")
+	if v == nil {
+		info.Content = append(info.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "version"}, &yaml.Node{Kind: yaml.ScalarNode})
+		v = info.Content[len(info.Content)-1]
+	}
+	v.Value = version
+	v.Tag = "!!str"
+	v.Style = yaml.DoubleQuotedStyle
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, fmt.Errorf("invalid API spec: %w", err)
+	}
+	return &APIHandler{spec: buf.Bytes()}, nil
+}
+
+func (h *APIHandler) ServeHTTP(resp http.ResponseWriter, _ *http.Request) {
+	resp.Header().Set("Content-Type", "application/x-yaml")
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(h.spec)
+}
+
+// mappingValue returns the value of the key of the mapping node, or of the mapping of the document node.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const testSpec = `swagger: "2.0"
+info:
+  title: Test API
+  version: 0.0.1
+paths: {}
+`
+
+func TestAPIHandler(t *testing.T) {
+	h, err := NewAPIHandler([]byte(testSpec), "v1.2.3")
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-yaml", w.Header().Get("Content-Type"))
+	var spec struct {
+		Swagger string `yaml:"swagger"`
+		Info    struct {
+			Title   string `yaml:"title"`
+			Version string `yaml:"version"`
+		} `yaml:"info"`
+	}
+	require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "2.0", spec.Swagger)
+	assert.Equal(t, "Test API", spec.Info.Title)
+	assert.Equal(t, "v1.2.3", spec.Info.Version)
+}
+
+func TestAPIHandler_WithoutVersion(t *testing.T) {
+	h, err := NewAPIHandler([]byte(testSpec), "")
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPath, nil))
+	assert.Equal(t, testSpec, w.Body.String())
+}
+
+func TestNewAPIHandler_Errors(t *testing.T) {
+	_, err := NewAPIHandler([]byte("info: ["), "v1.2.3")
+	assert.Error(t, err)
+	_, err = NewAPIHandler([]byte("swagger: \"2.0\"\n"), "v1.2.3")
+	assert.EqualError(t, err, "invalid API spec: no info")
+}
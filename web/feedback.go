@@ -0,0 +1,211 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	fp "path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/feedback"
+	"github.com/Financial-Times/public-suggestions-api/history"
+	"github.com/Financial-Times/public-suggestions-api/reqorigin"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/gorilla/mux"
+)
+
+const (
+	FeedbackPath      = "/content/{uuid}/suggestions/feedback"
+	FeedbackRatesPath = "/suggestions/feedback/rates"
+
+	defaultRatesWindow = 30 * 24 * time.Hour
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// FeedbackHandler records the editors decisions about the suggestions and reports the acceptance rates.
+type FeedbackHandler struct {
+	store feedback.Store
+	log   *logger.UPPLogger
+	now   func() time.Time
+	// History is optional, when set the source of the decisions without one is the source that suggested the concept
+	// in the stored responses of the content.
+	History history.Store
+}
+
+func NewFeedbackHandler(store feedback.Store, log *logger.UPPLogger) *FeedbackHandler {
+	return &FeedbackHandler{
+		store: store,
+		log:   log,
+		now:   time.Now,
+	}
+}
+
+type feedbackRequest struct {
+	// ContentType is optional, e.g. Article, it lets the suppression of rejected concepts work by content type.
+	ContentType string             `json:"contentType"`
+	Decisions   []feedbackDecision `json:"decisions"`
+}
+
+type feedbackDecision struct {
+	service.Suggestion
+	Source   string `json:"source"`
+	Decision string `json:"decision"`
+}
+
+func (h *FeedbackHandler) HandleFeedback(resp http.ResponseWriter, req *http.Request) {
+	tid := tidutils.GetTransactionIDFromRequest(req)
+	logEntry := h.log.WithTransactionID(tid)
+
+	uuid := mux.Vars(req)["uuid"]
+	if !uuidRegexp.MatchString(uuid) {
+		writeMessage(resp, http.StatusBadRequest, fmt.Sprintf("%q is not a valid content UUID", uuid))
+		return
+	}
+
+	var body feedbackRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		logEntry.WithError(err).Warn("Client error: invalid feedback payload")
+		writeMessage(resp, http.StatusBadRequest, "Payload should be a JSON object with decisions")
+		return
+	}
+	if len(body.Decisions) == 0 {
+		writeMessage(resp, http.StatusBadRequest, "Payload should contain at least one decision")
+		return
+	}
+
+	now := h.now().UTC()
+	origin := reqorigin.FromRequest(req)
+	records := make([]feedback.Record, 0, len(body.Decisions))
+	// the short name of the type, e.g. Article, as the suppression matches it against the one of the payloads
+	contentType := body.ContentType
+	if contentType != "" {
+		contentType = fp.Base(contentType)
+	}
+	var entries []history.Entry
+	unattributed := 0
+	for i, d := range body.Decisions {
+		source := d.Source
+		if source == "" {
+			if entries == nil {
+				entries = h.historyOf(uuid, tid)
+			}
+			source = suggestedBy(entries, d.ID)
+		}
+		if source == "" {
+			unattributed++
+			source = feedback.Unknown
+		}
+		r := feedback.Record{
+			Time:          now,
+			TransactionID: tid,
+			ContentUUID:   uuid,
+			ContentType:   contentType,
+			ConceptID:     d.ID,
+			Predicate:     d.Predicate,
+			ConceptType:   service.ConceptTypeName(d.Suggestion),
+			Source:        source,
+			Origin:        origin,
+			Decision:      d.Decision,
+		}
+		if err := r.Validate(); err != nil {
+			writeMessage(resp, http.StatusBadRequest, fmt.Sprintf("Decision %d is invalid: %v", i, err))
+			return
+		}
+		records = append(records, r)
+	}
+
+	if err := h.store.Add(records...); err != nil {
+		logEntry.WithError(err).Error("Could not store the feedback")
+		writeMessage(resp, http.StatusInternalServerError, "Could not store the feedback")
+		return
+	}
+	if unattributed > 0 {
+		logEntry.WithUUID(uuid).Warnf("The source of %d decisions is unknown, it was not sent and the concepts are not in the history of the content", unattributed)
+	}
+	jsonResponse, _ := json.Marshal(map[string]int{"recorded": len(records), "unattributed": unattributed})
+	writeResponse(resp, http.StatusOK, jsonResponse)
+}
+
+// historyOf returns the stored responses of the content, newest first, none when there is no History.
+func (h *FeedbackHandler) historyOf(uuid, tid string) []history.Entry {
+	if h.History == nil {
+		return []history.Entry{}
+	}
+	entries, err := h.History.List(uuid)
+	if err != nil {
+		h.log.WithTransactionID(tid).WithUUID(uuid).WithError(err).Warn("Could not read the history of the content, the decisions without a source are unattributed")
+		return []history.Entry{}
+	}
+	newest := make([]history.Entry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		newest = append(newest, entries[i])
+	}
+	return newest
+}
+
+// suggestedBy returns the source that suggested the concept in the latest of the entries, empty when none did.
+// In an entry, the source that returned the concept wins over the ones whose raw suggestion of it was filtered out.
+func suggestedBy(entries []history.Entry, conceptID string) string {
+	uuid := fp.Base(conceptID)
+	returned := func(source service.SourceReport) []service.Suggestion { return source.Returned }
+	raw := func(source service.SourceReport) []service.Suggestion { return source.Suggestions }
+	for _, e := range entries {
+		for _, suggestionsOf := range []func(service.SourceReport) []service.Suggestion{returned, raw} {
+			for _, source := range e.Sources {
+				for _, s := range suggestionsOf(source) {
+					if fp.Base(s.ID) == uuid {
+						return source.Source
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// HandleRates reports the acceptance rates since the time given by the since query parameter, in RFC 3339 format,
+// or over the window query parameter, e.g. 168h. The default window is 30 days.
+func (h *FeedbackHandler) HandleRates(resp http.ResponseWriter, req *http.Request) {
+	since, err := h.ratesSince(req)
+	if err != nil {
+		writeMessage(resp, http.StatusBadRequest, err.Error())
+		return
+	}
+	rates, err := feedback.AcceptanceRates(h.store, since)
+	if err != nil {
+		h.log.WithTransactionID(tidutils.GetTransactionIDFromRequest(req)).WithError(err).Error("Could not compute the acceptance rates")
+		writeMessage(resp, http.StatusInternalServerError, "Could not compute the acceptance rates")
+		return
+	}
+	jsonResponse, _ := json.Marshal(rates)
+	writeResponse(resp, http.StatusOK, jsonResponse)
+}
+
+func (h *FeedbackHandler) ratesSince(req *http.Request) (time.Time, error) {
+	query := req.URL.Query()
+	if s := query.Get("since"); s != "" {
+		since, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return since, fmt.Errorf("since %q is not an RFC 3339 time", s)
+		}
+		return since, nil
+	}
+	window := defaultRatesWindow
+	if w := query.Get("window"); w != "" {
+		var err error
+		window, err = time.ParseDuration(w)
+		if err != nil || window <= 0 {
+			return time.Time{}, fmt.Errorf("window %q is not a positive duration", w)
+		}
+	}
+	return h.now().UTC().Add(-window), nil
+}
+
+func writeMessage(resp http.ResponseWriter, status int, message string) {
+	jsonResponse, _ := json.Marshal(map[string]string{"message": message})
+	writeResponse(resp, status, jsonResponse)
+}
@@ -0,0 +1,201 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/feedback"
+	"github.com/Financial-Times/public-suggestions-api/history"
+	"github.com/Financial-Times/public-suggestions-api/reqorigin"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const contentUUID = "f1b2c3d4-0000-4000-8000-000000000001"
+
+func newFeedbackRouter(t *testing.T, now time.Time) (*mux.Router, feedback.Store) {
+	store, err := feedback.OpenBoltStore(filepath.Join(t.TempDir(), "feedback.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	handler := NewFeedbackHandler(store, logger.NewUPPLogger("test-logger", "panic"))
+	handler.now = func() time.Time { return now }
+	router := mux.NewRouter()
+	router.HandleFunc(FeedbackPath, handler.HandleFeedback).Methods(http.MethodPost)
+	router.HandleFunc(FeedbackRatesPath, handler.HandleRates).Methods(http.MethodGet)
+	return router, store
+}
+
+func TestFeedbackHandler_HandleFeedback(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	router, store := newFeedbackRouter(t, now)
+
+	req := httptest.NewRequest(http.MethodPost, "/content/"+contentUUID+"/suggestions/feedback", strings.NewReader(`{"decisions":[
+		{"id":"http://www.ft.com/thing/person","predicate":"http://www.ft.com/ontology/annotation/mentions","type":"http://www.ft.com/ontology/person/Person","source":"Ontotext Suggestion API","decision":"accepted"},
+		{"id":"http://www.ft.com/thing/topic","type":"http://www.ft.com/ontology/Topic","decision":"rejected"}]}`))
+	req.Header.Set("X-Request-Id", "tid_test")
+	reqorigin.SetHeader(req, "spark")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"recorded":2,"unattributed":1}`, w.Body.String())
+
+	var records []feedback.Record
+	require.NoError(t, store.Since(time.Time{}, func(r feedback.Record) error {
+		records = append(records, r)
+		return nil
+	}))
+	assert.Equal(t, []feedback.Record{
+		{Time: now, TransactionID: "tid_test", ContentUUID: contentUUID, ConceptID: "http://www.ft.com/thing/person", Predicate: "http://www.ft.com/ontology/annotation/mentions", ConceptType: "Person", Source: "Ontotext Suggestion API", Origin: "spark", Decision: feedback.Accepted},
+		{Time: now, TransactionID: "tid_test", ContentUUID: contentUUID, ConceptID: "http://www.ft.com/thing/topic", ConceptType: "Topic", Source: feedback.Unknown, Origin: "spark", Decision: feedback.Rejected},
+	}, records)
+}
+
+func TestFeedbackHandler_HandleFeedbackSourceFromHistory(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	store, err := feedback.OpenBoltStore(filepath.Join(t.TempDir(), "feedback.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	historyStore, err := history.OpenBoltStore(filepath.Join(t.TempDir(), "history.db"), 10)
+	require.NoError(t, err)
+	t.Cleanup(func() { historyStore.Close() })
+
+	person := service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/person"}}
+	topic := service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/topic"}}
+	require.NoError(t, historyStore.Add(history.Entry{Time: now.Add(-2 * time.Hour), ContentUUID: contentUUID, Sources: []service.SourceReport{
+		{Source: "Authors Suggestion API", Suggestions: []service.Suggestion{person}, Returned: []service.Suggestion{person}},
+	}}))
+	require.NoError(t, historyStore.Add(history.Entry{Time: now.Add(-time.Hour), ContentUUID: contentUUID, Sources: []service.SourceReport{
+		{Source: "Ontotext Suggestion API", Suggestions: []service.Suggestion{person, topic}, Returned: []service.Suggestion{topic}},
+	}}))
+
+	handler := NewFeedbackHandler(store, logger.NewUPPLogger("test-logger", "panic"))
+	handler.now = func() time.Time { return now }
+	handler.History = historyStore
+	router := mux.NewRouter()
+	router.HandleFunc(FeedbackPath, handler.HandleFeedback).Methods(http.MethodPost)
+
+	req := httptest.NewRequest(http.MethodPost, "/content/"+contentUUID+"/suggestions/feedback", strings.NewReader(`{"decisions":[
+		{"id":"http://api.ft.com/things/person","decision":"accepted"},
+		{"id":"http://www.ft.com/thing/topic","decision":"rejected"},
+		{"id":"http://www.ft.com/thing/topic","source":"Authors Suggestion API","decision":"rejected"},
+		{"id":"http://www.ft.com/thing/location","decision":"rejected"}]}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"recorded":4,"unattributed":1}`, w.Body.String())
+	var sources []string
+	require.NoError(t, store.Since(time.Time{}, func(r feedback.Record) error {
+		sources = append(sources, r.Source)
+		return nil
+	}))
+	assert.Equal(t, []string{"Ontotext Suggestion API", "Ontotext Suggestion API", "Authors Suggestion API", feedback.Unknown}, sources,
+		"the latest response suggesting the concept gives its source, the sent source is kept")
+}
+
+func TestFeedbackHandler_HandleFeedbackContentTypeURI(t *testing.T) {
+	router, store := newFeedbackRouter(t, time.Now())
+
+	req := httptest.NewRequest(http.MethodPost, "/content/"+contentUUID+"/suggestions/feedback", strings.NewReader(`{"contentType":"http://www.ft.com/ontology/content/Article",
+		"decisions":[{"id":"http://www.ft.com/thing/topic","source":"Ontotext Suggestion API","decision":"rejected"}]}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var contentTypes []string
+	require.NoError(t, store.Since(time.Time{}, func(r feedback.Record) error {
+		contentTypes = append(contentTypes, r.ContentType)
+		return nil
+	}))
+	assert.Equal(t, []string{"Article"}, contentTypes, "the content type is stored as the suppression matches it")
+
+	suppressor, err := feedback.NewSuppressor(store, feedback.SuppressionConfig{Window: time.Hour, RejectionRate: 0.5, MinDecisions: 1}, logger.NewUPPLogger("test-logger", "panic"))
+	require.NoError(t, err)
+	require.NoError(t, suppressor.Refresh())
+	assert.True(t, suppressor.IsSuppressed("http://www.ft.com/thing/topic", "", "Article"))
+}
+
+func TestSuggestedBy(t *testing.T) {
+	person := service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/person"}}
+	topic := service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/topic"}}
+	entries := []history.Entry{{Sources: []service.SourceReport{
+		{Source: "Authors Suggestion API", Suggestions: []service.Suggestion{person, topic}, Returned: []service.Suggestion{topic}},
+		{Source: "Ontotext Suggestion API", Suggestions: []service.Suggestion{person}, Returned: []service.Suggestion{person}},
+	}}}
+
+	assert.Equal(t, "Ontotext Suggestion API", suggestedBy(entries, "http://www.ft.com/thing/person"),
+		"the source that returned the concept wins over an earlier source that only suggested it")
+	assert.Equal(t, "Authors Suggestion API", suggestedBy(entries, "http://api.ft.com/things/topic"))
+	entries[0].Sources[1].Returned = nil
+	assert.Equal(t, "Authors Suggestion API", suggestedBy(entries, "http://www.ft.com/thing/person"),
+		"without a source returning the concept, the first that suggested it")
+	assert.Equal(t, "", suggestedBy(entries, "http://www.ft.com/thing/location"))
+}
+
+func TestFeedbackHandler_HandleFeedbackClientErrors(t *testing.T) {
+	router, _ := newFeedbackRouter(t, time.Now())
+
+	testCases := []struct {
+		uuid    string
+		body    string
+		message string
+	}{
+		{uuid: "not-a-uuid", body: `{"decisions":[{"id":"c","decision":"accepted"}]}`, message: `"not-a-uuid" is not a valid content UUID`},
+		{uuid: contentUUID, body: `[]`, message: "Payload should be a JSON object with decisions"},
+		{uuid: contentUUID, body: `{"decisions":[]}`, message: "Payload should contain at least one decision"},
+		{uuid: contentUUID, body: `{"decisions":[{"id":"c","decision":"accepted"},{"id":"c","decision":"ignored"}]}`, message: `Decision 1 is invalid: decision "ignored" is neither accepted nor rejected`},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/content/"+tc.uuid+"/suggestions/feedback", strings.NewReader(tc.body)))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, tc.message, body["message"])
+	}
+}
+
+func TestFeedbackHandler_HandleRates(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	router, store := newFeedbackRouter(t, now)
+	require.NoError(t, store.Add(
+		feedback.Record{Time: now.Add(-40 * 24 * time.Hour), ConceptID: "old", ConceptType: "Person", Source: "Ontotext Suggestion API", Decision: feedback.Rejected},
+		feedback.Record{Time: now.Add(-time.Hour), ConceptID: "a", ConceptType: "Person", Source: "Ontotext Suggestion API", Origin: "spark", Decision: feedback.Accepted},
+		feedback.Record{Time: now.Add(-2 * time.Hour), ConceptID: "b", ConceptType: "Topic", Source: "Ontotext Suggestion API", Origin: "spark", Decision: feedback.Rejected},
+	))
+
+	testCases := []struct {
+		query    string
+		accepted int
+		rejected int
+	}{
+		{query: "", accepted: 1, rejected: 1},
+		{query: "?window=90m", accepted: 1},
+		{query: "?since=2026-01-01T00:00:00Z", accepted: 1, rejected: 2},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, FeedbackRatesPath+tc.query, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var rates feedback.Rates
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rates))
+		assert.Equal(t, tc.accepted, rates.Overall.Accepted, tc.query)
+		assert.Equal(t, tc.rejected, rates.Overall.Rejected, tc.query)
+	}
+
+	for _, query := range []string{"?window=-1h", "?window=week", "?since=yesterday"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, FeedbackRatesPath+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
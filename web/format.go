@@ -0,0 +1,88 @@
+package web
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Financial-Times/public-suggestions-api/rdf"
+	"github.com/Financial-Times/public-suggestions-api/service"
+)
+
+const (
+	mediaTypeJSON = "application/json"
+	// formatGrouped is the format query param value of the JSON responses with the suggestions grouped by concept type.
+	formatGrouped = "grouped"
+)
+
+// responseFormats are the media types of the suggestions responses, in order of preference.
+var responseFormats = []string{mediaTypeJSON, rdf.MediaTypeJSONLD, rdf.MediaTypeTurtle}
+
+// negotiateFormat returns the response format the Accept header prefers, JSON when it accepts none of them.
+func negotiateFormat(accept string) string {
+	best, bestQuality := mediaTypeJSON, 0.0
+	for _, format := range responseFormats {
+		if quality := acceptedQuality(accept, format); quality > bestQuality {
+			best, bestQuality = format, quality
+		}
+	}
+	return best
+}
+
+// acceptedQuality returns the quality of the most specific media range of the Accept header matching the format.
+func acceptedQuality(accept, format string) float64 {
+	quality, specificity := 0.0, -1
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+		s := -1
+		switch {
+		case mediaType == format:
+			s = 2
+		case strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(format, strings.TrimSuffix(mediaType, "*")):
+			s = 1
+		case mediaType == "*/*":
+			s = 0
+		}
+		if s <= specificity {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		quality, specificity = q, s
+	}
+	return quality
+}
+
+// writeSuggestions writes the suggestions for the content of the payload in the format, grouped only applies to JSON.
+func writeSuggestions(resp http.ResponseWriter, format string, grouped bool, payload []byte, suggestions service.SuggestionsResponse) {
+	resp.Header().Add("Vary", "Accept")
+	switch format {
+	case rdf.MediaTypeJSONLD:
+		//ignoring marshalling errors as the graph only has strings
+		jsonLD, _ := rdf.NewGraph(payload, suggestions.Suggestions).JSONLD()
+		resp.Header().Set("Content-Type", rdf.MediaTypeJSONLD)
+		resp.WriteHeader(http.StatusOK)
+		resp.Write(jsonLD)
+	case rdf.MediaTypeTurtle:
+		resp.Header().Set("Content-Type", rdf.MediaTypeTurtle+"; charset=utf-8")
+		resp.WriteHeader(http.StatusOK)
+		resp.Write(rdf.NewGraph(payload, suggestions.Suggestions).Turtle())
+	default:
+		var response interface{} = suggestions
+		if grouped {
+			response = suggestions.Grouped()
+		}
+		//ignoring marshalling errors as neither UnsupportedTypeError nor UnsupportedValueError is possible
+		jsonResponse, _ := json.Marshal(response)
+		writeResponse(resp, http.StatusOK, jsonResponse)
+	}
+}
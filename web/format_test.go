@@ -0,0 +1,26 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	testCases := map[string]string{
+		"":                                       "application/json",
+		"application/json":                       "application/json",
+		"application/ld+json":                    "application/ld+json",
+		"text/turtle":                            "text/turtle",
+		"text/turtle;q=0.5, application/ld+json": "application/ld+json",
+		"text/*":                                 "text/turtle",
+		"*/*;q=0.1, text/turtle;q=0.9":           "text/turtle",
+		"text/turtle;q=0, */*":                   "application/json",
+		"text/html,application/xhtml+xml,*/*;q=0.8": "application/json",
+		"image/png":        "application/json",
+		"not a media type": "application/json",
+	}
+	for accept, expected := range testCases {
+		assert.Equal(t, expected, negotiateFormat(accept), accept)
+	}
+}
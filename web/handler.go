@@ -5,17 +5,54 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/audit"
+	"github.com/Financial-Times/public-suggestions-api/content"
+	"github.com/Financial-Times/public-suggestions-api/history"
 	"github.com/Financial-Times/public-suggestions-api/reqorigin"
 	"github.com/Financial-Times/public-suggestions-api/service"
 	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/gorilla/mux"
 )
 
+const (
+	experimentHeader = "X-Suggestions-Experiment"
+	variantHeader    = "X-Suggestions-Variant"
+)
+
+// payloadConverters convert the payloads of the other media types HandleSuggestion accepts into JSON content.
+var payloadConverters = map[string]func([]byte) ([]byte, error){
+	"text/plain":      content.FromText,
+	"text/html":       content.FromHTML,
+	"application/xml": content.FromXML,
+	"text/xml":        content.FromXML,
+}
+
 type RequestHandler struct {
 	suggester *service.AggregateSuggester
 	log       *logger.UPPLogger
+	// Auditor is optional, when set every aggregated request is written to the audit log.
+	Auditor *audit.Auditor
+	// History is optional, when set the response of every content with an id is stored.
+	History history.Store
+	// ContentReader is needed by HandleStoredContentSuggestion only.
+	ContentReader service.ContentReader
+	// ContentValidator is optional, when set the posted payloads with invalid fields are rejected.
+	ContentValidator *content.Validator
+	// Normaliser is optional, when set the body fields of the payloads are rewritten before they are sent to the suggesters.
+	Normaliser *content.Normaliser
+	// MaxPayloadBytes is the size of the largest payload accepted by HandleSuggestion, 0 for no limit.
+	MaxPayloadBytes int64
+}
+
+type validationErrorResponse struct {
+	Message string               `json:"message"`
+	Errors  []content.FieldError `json:"errors"`
 }
 
 func NewRequestHandler(s *service.AggregateSuggester, log *logger.UPPLogger) *RequestHandler {
@@ -30,7 +67,17 @@ func (h *RequestHandler) HandleSuggestion(resp http.ResponseWriter, req *http.Re
 	tid := tidutils.GetTransactionIDFromRequest(req)
 	logEntry := h.log.WithTransactionID(tid)
 
-	body, err := ioutil.ReadAll(req.Body)
+	reader := req.Body
+	if h.MaxPayloadBytes > 0 {
+		reader = http.MaxBytesReader(resp, req.Body, h.MaxPayloadBytes)
+	}
+	body, err := ioutil.ReadAll(reader)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		logEntry.WithError(err).Warn("Client error: payload too large")
+		writeMessage(resp, http.StatusRequestEntityTooLarge, fmt.Sprintf("Payload should not be larger than %d bytes", tooLarge.Limit))
+		return
+	}
 	if err != nil {
 		logEntry.WithError(err).Error("Error while reading payload")
 		writeResponse(resp, http.StatusBadRequest, []byte(`{"message": "Error while reading payload"}`))
@@ -38,17 +85,113 @@ func (h *RequestHandler) HandleSuggestion(resp http.ResponseWriter, req *http.Re
 	}
 
 	logEntry.Debugf("request body: %s", string(body))
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		convert, ok := payloadConverters[mediaType]
+		if err != nil || !ok && mediaType != "application/json" {
+			logEntry.Warnf("Client error: unsupported Content-Type %q", contentType)
+			writeMessage(resp, http.StatusUnsupportedMediaType, fmt.Sprintf("Content-Type %s is not supported, the payload should be application/json, text/plain, text/html or application/xml", contentType))
+			return
+		}
+		if ok {
+			if body, err = convert(body); err != nil {
+				logEntry.WithError(err).Warnf("Client error: payload is not valid %s", mediaType)
+				writeMessage(resp, http.StatusBadRequest, fmt.Sprintf("Payload is not valid %s: %v", mediaType, err))
+				return
+			}
+		}
+	}
 	validPayload, err := validatePayload(body)
 	if !validPayload {
 		logEntry.WithError(err).Error("Client error: payload should be a non-empty JSON object")
 		writeResponse(resp, http.StatusBadRequest, []byte(`{"message": "Payload should be a non-empty JSON object"}`))
 		return
 	}
+	if h.ContentValidator != nil {
+		var validationErr *content.ValidationError
+		if _, err := h.ContentValidator.Parse(body); errors.As(err, &validationErr) {
+			logEntry.WithError(err).Warn("Client error: invalid content")
+			jsonResponse, _ := json.Marshal(validationErrorResponse{Message: "Payload is not valid content", Errors: validationErr.Errors})
+			writeResponse(resp, http.StatusBadRequest, jsonResponse)
+			return
+		}
+	}
 
-	suggestions, err := h.suggester.GetSuggestions(body, tid, reqorigin.FromRequest(req))
+	h.suggest(resp, req, tid, body)
+}
+
+// HandleStoredContentSuggestion suggests annotations for the content with the uuid of the path, read from the ContentReader.
+func (h *RequestHandler) HandleStoredContentSuggestion(resp http.ResponseWriter, req *http.Request) {
+	tid := tidutils.GetTransactionIDFromRequest(req)
+	logEntry := h.log.WithTransactionID(tid)
+
+	uuid := mux.Vars(req)["uuid"]
+	if !uuidRegexp.MatchString(uuid) {
+		writeMessage(resp, http.StatusBadRequest, fmt.Sprintf("%q is not a valid content UUID", uuid))
+		return
+	}
+
+	body, err := h.ContentReader.GetContent(req.Context(), uuid, tid, reqorigin.FromRequest(req))
+	if errors.Is(err, service.ContentNotFoundError) {
+		logEntry.WithUUID(uuid).Warn("Client error: content not found")
+		writeMessage(resp, http.StatusNotFound, fmt.Sprintf("Content %s not found", uuid))
+		return
+	}
+	if err != nil {
+		logEntry.WithUUID(uuid).WithError(err).Error("Error while reading the content")
+		writeMessage(resp, http.StatusServiceUnavailable, "Reading the content failed")
+		return
+	}
+	if validPayload, err := validatePayload(body); !validPayload {
+		logEntry.WithUUID(uuid).WithError(err).Error("The stored content is not a non-empty JSON object")
+		writeMessage(resp, http.StatusBadGateway, "The stored content is not a non-empty JSON object")
+		return
+	}
+
+	h.suggest(resp, req, tid, body)
+}
+
+// suggest aggregates the suggestions for the payload and writes the response.
+func (h *RequestHandler) suggest(resp http.ResponseWriter, req *http.Request, tid string, body []byte) {
+	logEntry := h.log.WithTransactionID(tid)
+	format := req.URL.Query().Get("format")
+	if format != "" && format != formatGrouped {
+		logEntry.Warnf("Client error: unknown format %q", format)
+		writeMessage(resp, http.StatusBadRequest, fmt.Sprintf("Format %q is not supported, the only format is %s", format, formatGrouped))
+		return
+	}
+	origin := reqorigin.FromRequest(req)
+	var transformations []content.Transformation
+	if h.Normaliser != nil {
+		normalised, changes, err := h.Normaliser.Normalise(body)
+		if err != nil {
+			logEntry.WithError(err).Warn("Could not normalise the payload, it is sent as it is")
+		} else {
+			body, transformations = normalised, changes
+		}
+	}
+	timings := &service.Timings{}
+	report := &service.Report{}
+	ctx := service.ContextWithTimings(req.Context(), timings)
+	ctx = service.ContextWithReport(ctx, report)
+	if variant := req.Header.Get(variantHeader); variant != "" {
+		ctx = service.ContextWithVariant(ctx, variant)
+	}
+	suggestions, err := h.suggester.GetSuggestions(ctx, body, tid, origin)
+	resp.Header().Set(serverTimingHeader, serverTiming(timings.Stages()))
+	if experiment, variant := report.Variant(); experiment != "" {
+		resp.Header().Set(experimentHeader, experiment)
+		resp.Header().Set(variantHeader, variant)
+	}
+	if errors.Is(err, service.UnknownVariantError) {
+		logEntry.WithError(err).Warn("Client error: unknown experiment variant")
+		writeMessage(resp, http.StatusBadRequest, fmt.Sprintf("The %s header is not a variant of the experiment", variantHeader))
+		return
+	}
 	if err != nil {
 		errMsg := "aggregating suggestions failed!"
 		logEntry.WithError(err).Error(errMsg)
+		h.audit(tid, origin, body, report, nil, err)
 		writeResponse(resp, http.StatusServiceUnavailable, []byte(fmt.Sprintf(`{"message": "%s"}`, errMsg)))
 		return
 	}
@@ -56,10 +199,58 @@ func (h *RequestHandler) HandleSuggestion(resp http.ResponseWriter, req *http.Re
 	if len(suggestions.Suggestions) == 0 {
 		logEntry.Warn("Suggestions are empty")
 	}
-	//ignoring marshalling errors as neither UnsupportedTypeError nor UnsupportedValueError is possible
-	jsonResponse, _ := json.Marshal(suggestions)
+	if withTimings, _ := strconv.ParseBool(req.URL.Query().Get("timings")); withTimings {
+		suggestions.Timings = timings.Stages()
+	}
+	suggestions.Transformations = transformations
+	h.audit(tid, origin, body, report, &suggestions, nil)
+	h.record(tid, origin, body, report, suggestions)
+
+	writeSuggestions(resp, negotiateFormat(req.Header.Get("Accept")), format == formatGrouped, body, suggestions)
+}
+
+func (h *RequestHandler) audit(tid, origin string, payload []byte, report *service.Report, suggestions *service.SuggestionsResponse, err error) {
+	if h.Auditor == nil {
+		return
+	}
+	experiment, variant := report.Variant()
+	record := audit.Record{
+		TransactionID: tid,
+		Origin:        origin,
+		Experiment:    experiment,
+		Variant:       variant,
+		Sources:       report.Sources(),
+		Dropped:       report.Dropped(),
+		Response:      suggestions,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	h.Auditor.Audit(record, payload)
+}
 
-	writeResponse(resp, http.StatusOK, jsonResponse)
+func (h *RequestHandler) record(tid, origin string, payload []byte, report *service.Report, suggestions service.SuggestionsResponse) {
+	if h.History == nil {
+		return
+	}
+	contentUUID := service.ContentUUID(payload)
+	if contentUUID == "" {
+		return
+	}
+	experiment, variant := report.Variant()
+	err := h.History.Add(history.Entry{
+		Time:          time.Now().UTC(),
+		TransactionID: tid,
+		ContentUUID:   contentUUID,
+		Origin:        origin,
+		Experiment:    experiment,
+		Variant:       variant,
+		Suggestions:   suggestions.Suggestions,
+		Sources:       report.Sources(),
+	})
+	if err != nil {
+		h.log.WithTransactionID(tid).WithError(err).Error("Could not store the suggestions history")
+	}
 }
 
 func validatePayload(content []byte) (bool, error) {
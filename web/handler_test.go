@@ -2,6 +2,7 @@ package web
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
@@ -12,8 +13,11 @@ import (
 
 	"github.com/Financial-Times/go-fthealth/v1_1"
 	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/audit"
+	"github.com/Financial-Times/public-suggestions-api/content"
 	"github.com/Financial-Times/public-suggestions-api/reqorigin"
 	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -56,7 +60,7 @@ func (r *faultyReader) Close() error {
 	return nil
 }
 
-func (s *mockSuggesterService) GetSuggestions(payload []byte, tid, origin string) (service.SuggestionsResponse, error) {
+func (s *mockSuggesterService) GetSuggestions(ctx context.Context, payload []byte, tid, origin string) (service.SuggestionsResponse, error) {
 	args := s.Called(payload, tid, origin)
 	return args.Get(0).(service.SuggestionsResponse), args.Error(1)
 }
@@ -255,6 +259,229 @@ func TestRequestHandler_HandleSuggestionEmptyJsonRequest(t *testing.T) {
 	mockClient.AssertExpectations(t)       //no calls
 }
 
+func TestRequestHandler_HandleSuggestionInvalidContent(t *testing.T) {
+	req := httptest.NewRequest("POST", "/content/suggest", strings.NewReader(`{"foo":1,"title":2}`))
+	req.Header.Add("X-Request-Id", "tid_test")
+	w := httptest.NewRecorder()
+
+	log := logger.NewUPPLogger("test-logger", "panic")
+	mockSuggester := new(mockSuggesterService)
+	handler := NewRequestHandler(service.NewAggregateSuggester(log, nil, nil, nil, mockSuggester), log)
+	validator, err := content.NewValidator(content.Rules{TextFields: []string{"title", "bodyXML"}})
+	require.NoError(t, err)
+	handler.ContentValidator = validator
+	handler.HandleSuggestion(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"Payload is not valid content","errors":[`+
+		`{"field":"title","message":"should be a string, not number"},`+
+		`{"field":"bodyXML","message":"has no text, the content needs one of title, bodyXML"}]}`, w.Body.String())
+	mockSuggester.AssertExpectations(t) //no calls
+}
+
+func TestRequestHandler_HandleSuggestionTooLarge(t *testing.T) {
+	req := httptest.NewRequest("POST", "/content/suggest", strings.NewReader(`{"bodyXML":"`+strings.Repeat("a", 100)+`"}`))
+	w := httptest.NewRecorder()
+
+	log := logger.NewUPPLogger("test-logger", "panic")
+	mockSuggester := new(mockSuggesterService)
+	handler := NewRequestHandler(service.NewAggregateSuggester(log, nil, nil, nil, mockSuggester), log)
+	handler.MaxPayloadBytes = 64
+	handler.HandleSuggestion(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.JSONEq(t, `{"message":"Payload should not be larger than 64 bytes"}`, w.Body.String())
+	mockSuggester.AssertExpectations(t) //no calls
+}
+
+func TestRequestHandler_HandleSuggestionNormalised(t *testing.T) {
+	body := []byte(`{"title":"Test title","bodyXML":"<body><p>Test  body</p></body>"}`)
+	req := httptest.NewRequest("POST", "/content/suggest", bytes.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_test")
+	reqorigin.SetHeader(req, "tests_origin")
+	w := httptest.NewRecorder()
+
+	log := logger.NewUPPLogger("test-logger", "panic")
+	mockSuggester := new(mockSuggesterService)
+	mockSuggester.On("GetSuggestions", []byte(`{"bodyXML":"Test body","title":"Test title"}`), "tid_test", "tests_origin").
+		Return(service.SuggestionsResponse{Suggestions: []service.Suggestion{}}, nil)
+	mockSuggester.On("FilterSuggestions", mock.AnythingOfType("[]service.Suggestion")).Return([]service.Suggestion{})
+	blacklisterMock := new(mockHttpClient)
+	blacklisterMock.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":[]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	blacklister := service.NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
+
+	handler := NewRequestHandler(service.NewAggregateSuggester(log, nil, &service.BroaderConceptsProvider{}, blacklister, mockSuggester), log)
+	normaliser, err := content.NewNormaliser(content.MarkupStrip, 0)
+	require.NoError(t, err)
+	handler.Normaliser = normaliser
+	handler.HandleSuggestion(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"suggestions":[],"transformations":[{"field":"bodyXML","transformation":"stripped","originalLength":30,"length":9}]}`, w.Body.String())
+	mockSuggester.AssertExpectations(t)
+}
+
+func TestRequestHandler_HandleSuggestionConvertedPayload(t *testing.T) {
+	testCases := []struct {
+		contentType string
+		body        string
+		payload     string
+	}{
+		{"text/plain; charset=utf-8", "Test body", `{"bodyXML":"<body><p>Test body</p></body>"}`},
+		{"text/html", "<html><head><title>Test title</title></head><body><p>Test body</p></body></html>", `{"bodyXML":"<body><p>Test body</p></body>","title":"Test title"}`},
+		{"application/xml", "<content><title>Test title</title></content>", `{"title":"Test title"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.contentType, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/content/suggest", strings.NewReader(tc.body))
+			req.Header.Add("X-Request-Id", "tid_test")
+			req.Header.Add("Content-Type", tc.contentType)
+			reqorigin.SetHeader(req, "tests_origin")
+			w := httptest.NewRecorder()
+
+			log := logger.NewUPPLogger("test-logger", "panic")
+			mockSuggester := new(mockSuggesterService)
+			mockSuggester.On("GetSuggestions", []byte(tc.payload), "tid_test", "tests_origin").
+				Return(service.SuggestionsResponse{Suggestions: []service.Suggestion{}}, nil)
+			mockSuggester.On("FilterSuggestions", mock.AnythingOfType("[]service.Suggestion")).Return([]service.Suggestion{})
+			blacklisterMock := new(mockHttpClient)
+			blacklisterMock.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":[]}`)),
+				StatusCode: http.StatusOK,
+			}, nil)
+			blacklister := service.NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
+
+			handler := NewRequestHandler(service.NewAggregateSuggester(log, nil, &service.BroaderConceptsProvider{}, blacklister, mockSuggester), log)
+			handler.HandleSuggestion(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, `{"suggestions":[]}`, w.Body.String())
+			mockSuggester.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequestHandler_HandleSuggestionUnconvertiblePayload(t *testing.T) {
+	testCases := []struct {
+		contentType string
+		body        string
+		status      int
+		message     string
+	}{
+		{"application/x-www-form-urlencoded", "title=Test", http.StatusUnsupportedMediaType,
+			"Content-Type application/x-www-form-urlencoded is not supported, the payload should be application/json, text/plain, text/html or application/xml"},
+		{"text/", "Test body", http.StatusUnsupportedMediaType,
+			"Content-Type text/ is not supported, the payload should be application/json, text/plain, text/html or application/xml"},
+		{"text/plain", "  ", http.StatusBadRequest, "Payload is not valid text/plain: the text is empty"},
+		{"application/xml", "<content><title>Test</content>", http.StatusBadRequest,
+			"Payload is not valid application/xml: XML syntax error on line 1: element <title> closed by </content>"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.contentType, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/content/suggest", strings.NewReader(tc.body))
+			req.Header.Add("Content-Type", tc.contentType)
+			w := httptest.NewRecorder()
+
+			log := logger.NewUPPLogger("test-logger", "panic")
+			mockSuggester := new(mockSuggesterService)
+			handler := NewRequestHandler(service.NewAggregateSuggester(log, nil, nil, nil, mockSuggester), log)
+			handler.HandleSuggestion(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.JSONEq(t, `{"message":"`+tc.message+`"}`, w.Body.String())
+			mockSuggester.AssertExpectations(t) //no calls
+		})
+	}
+}
+
+func TestRequestHandler_HandleSuggestionAsTurtle(t *testing.T) {
+	body := []byte(`{"uuid":"9d5e441e-0b02-11e8-8eb7-42f857ea9f09","bodyXML":"Test body"}`)
+	req := httptest.NewRequest("POST", "/content/suggest", bytes.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_test")
+	req.Header.Add("Accept", "text/turtle")
+	reqorigin.SetHeader(req, "tests_origin")
+	w := httptest.NewRecorder()
+
+	log := logger.NewUPPLogger("test-logger", "panic")
+	suggestions := []service.Suggestion{{Concept: service.Concept{ID: "http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a50", Type: "http://www.ft.com/ontology/Topic", PrefLabel: "Markets"}}}
+	mockSuggester := new(mockSuggesterService)
+	mockSuggester.On("GetSuggestions", body, "tid_test", "tests_origin").Return(service.SuggestionsResponse{Suggestions: suggestions}, nil)
+	mockSuggester.On("FilterSuggestions", suggestions).Return(suggestions)
+	blacklisterMock := new(mockHttpClient)
+	blacklisterMock.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":[]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	blacklister := service.NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
+	concordanceClient := new(mockHttpClient)
+	concordanceClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"concepts":{"9332270e-f959-3f55-9153-d30acd0d0a50":{"id":"http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a50","type":"http://www.ft.com/ontology/Topic","prefLabel":"Markets"}}}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	mockConcordance := &service.ConcordanceService{ConcordanceBaseURL: "concordanceBaseURL", ConcordanceEndpoint: "concordanceEndpoint", Client: concordanceClient}
+	broaderClient := new(mockHttpClient)
+	broaderClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{Body: ioutil.NopCloser(strings.NewReader("")), StatusCode: http.StatusOK}, nil)
+
+	handler := NewRequestHandler(service.NewAggregateSuggester(log, mockConcordance, &service.BroaderConceptsProvider{Client: broaderClient}, blacklister, mockSuggester), log)
+	handler.HandleSuggestion(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/turtle; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	assert.Equal(t, `@prefix skos: <http://www.w3.org/2004/02/skos/core#> .
+
+<http://www.ft.com/thing/9d5e441e-0b02-11e8-8eb7-42f857ea9f09> <http://www.ft.com/ontology/annotation/mentions> <http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a50> .
+<http://www.ft.com/thing/9332270e-f959-3f55-9153-d30acd0d0a50> a <http://www.ft.com/ontology/Topic> ;
+    skos:prefLabel "Markets" .
+`, w.Body.String())
+	mockSuggester.AssertExpectations(t)
+}
+
+func TestRequestHandler_HandleSuggestionGrouped(t *testing.T) {
+	body := []byte(`{"bodyXML":"Test body"}`)
+	req := httptest.NewRequest("POST", "/content/suggest?format=grouped", bytes.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_test")
+	reqorigin.SetHeader(req, "tests_origin")
+	w := httptest.NewRecorder()
+
+	log := logger.NewUPPLogger("test-logger", "panic")
+	mockSuggester := new(mockSuggesterService)
+	mockSuggester.On("GetSuggestions", body, "tid_test", "tests_origin").Return(service.SuggestionsResponse{Suggestions: []service.Suggestion{}}, nil)
+	mockSuggester.On("FilterSuggestions", mock.AnythingOfType("[]service.Suggestion")).Return([]service.Suggestion{})
+	blacklisterMock := new(mockHttpClient)
+	blacklisterMock.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(`{"uuids":[]}`)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	blacklister := service.NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
+
+	handler := NewRequestHandler(service.NewAggregateSuggester(log, nil, &service.BroaderConceptsProvider{}, blacklister, mockSuggester), log)
+	handler.HandleSuggestion(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"count":0,"groups":[{"name":"author","count":0,"suggestions":[]},{"name":"personSource","count":0,"suggestions":[]},`+
+		`{"name":"organisationSource","count":0,"suggestions":[]},{"name":"locationSource","count":0,"suggestions":[]},`+
+		`{"name":"topicSource","count":0,"suggestions":[]}]}`, w.Body.String())
+	mockSuggester.AssertExpectations(t)
+}
+
+func TestRequestHandler_HandleSuggestionUnknownFormat(t *testing.T) {
+	req := httptest.NewRequest("POST", "/content/suggest?format=flat", strings.NewReader(`{"bodyXML":"Test body"}`))
+	w := httptest.NewRecorder()
+
+	log := logger.NewUPPLogger("test-logger", "panic")
+	mockSuggester := new(mockSuggesterService)
+	handler := NewRequestHandler(service.NewAggregateSuggester(log, nil, nil, nil, mockSuggester), log)
+	handler.HandleSuggestion(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"Format \"flat\" is not supported, the only format is grouped"}`, w.Body.String())
+	mockSuggester.AssertExpectations(t) //no calls
+}
+
 func TestRequestHandler_HandleSuggestionErrorOnGetSuggestions(t *testing.T) {
 	expect := assert.New(t)
 
@@ -427,3 +654,211 @@ func TestRequestHandler_HandleSuggestionErrorOnGetConcordance(t *testing.T) {
 	mockPublicThings.AssertExpectations(t)
 	mockClient.AssertExpectations(t)
 }
+
+func TestRequestHandler_HandleSuggestionWithTimings(t *testing.T) {
+	expect := assert.New(t)
+
+	body := []byte(`{"byline":"Test byline","bodyXML":"Test body","title":"Test title"}`)
+	req := httptest.NewRequest("POST", "/content/suggest?timings=true", bytes.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_test")
+	w := httptest.NewRecorder()
+
+	suggestion := service.Suggestion{Concept: service.Concept{ID: "authors-suggestion-api", PrefLabel: "prefLabel2", Type: personType}}
+	handler, mockSuggester := newSingleSuggesterHandler(t, body, suggestion)
+	handler.HandleSuggestion(w, req)
+
+	expect.Equal(http.StatusOK, w.Code)
+	serverTiming := w.Header().Get("Server-Timing")
+	for _, stage := range []string{"suggest.mock-suggester-service;dur=", "concordance.mock-suggester-service;dur=", "blacklist;dur=", "broader;dur=", "total;dur="} {
+		expect.Contains(serverTiming, stage)
+	}
+
+	var resp service.SuggestionsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	expect.Equal([]service.Suggestion{suggestion}, resp.Suggestions)
+	names := []string{}
+	for _, stage := range resp.Timings {
+		names = append(names, stage.Name)
+	}
+	expect.ElementsMatch([]string{"total", "suggest.mock-suggester-service", "concordance.mock-suggester-service", "blacklist", "broader"}, names)
+	mockSuggester.AssertExpectations(t)
+}
+
+func TestRequestHandler_HandleSuggestionAudited(t *testing.T) {
+	expect := assert.New(t)
+
+	body := []byte(`{"id":"http://www.ft.com/thing/content","bodyXML":"Test body","title":"Test title"}`)
+	req := httptest.NewRequest("POST", "/content/suggest", bytes.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_test")
+	reqorigin.SetHeader(req, "tests_origin")
+	w := httptest.NewRecorder()
+
+	suggestion := service.Suggestion{Concept: service.Concept{ID: "authors-suggestion-api", PrefLabel: "prefLabel2", Type: personType}}
+	handler, _ := newSingleSuggesterHandler(t, body, suggestion)
+	buf := &bytes.Buffer{}
+	handler.Auditor = audit.New(audit.NewWriterSink(buf), audit.DefaultRedactedFields, logger.NewUPPLogger("test-logger", "panic"))
+	handler.HandleSuggestion(w, req)
+
+	expect.Equal(http.StatusOK, w.Code)
+	var record audit.Record
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	expect.Equal("tid_test", record.TransactionID)
+	expect.Equal("tests_origin", record.Origin)
+	expect.Equal("http://www.ft.com/thing/content", record.ContentID)
+	expect.Equal("[REDACTED]", record.Payload["bodyXML"])
+	expect.Equal([]service.SourceReport{{Source: "Mock suggester service", Suggestions: []service.Suggestion{suggestion}, Returned: []service.Suggestion{suggestion}}}, record.Sources)
+	expect.Empty(record.Dropped)
+	expect.Empty(w.Header().Get("X-Suggestions-Variant"))
+	expect.Equal([]service.Suggestion{suggestion}, record.Response.Suggestions)
+}
+
+func TestRequestHandler_HandleSuggestionInExperiment(t *testing.T) {
+	expect := assert.New(t)
+
+	body := []byte(`{"id":"http://www.ft.com/thing/content","title":"Test title"}`)
+	req := httptest.NewRequest("POST", "/content/suggest", bytes.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_test")
+	w := httptest.NewRecorder()
+
+	suggestion := service.Suggestion{Concept: service.Concept{ID: "authors-suggestion-api", PrefLabel: "prefLabel2", Type: personType}}
+	handler, _ := newSingleSuggesterHandler(t, body, suggestion)
+	experiment, err := service.NewExperiment("no-blacklist", service.Variant{Name: "treatment", Weight: 1, SkipFilters: []string{service.FilterBlacklist}})
+	require.NoError(t, err)
+	handler.suggester.Experiment = experiment
+	buf := &bytes.Buffer{}
+	handler.Auditor = audit.New(audit.NewWriterSink(buf), audit.DefaultRedactedFields, logger.NewUPPLogger("test-logger", "panic"))
+	handler.HandleSuggestion(w, req)
+
+	expect.Equal(http.StatusOK, w.Code)
+	expect.Equal("no-blacklist", w.Header().Get("X-Suggestions-Experiment"))
+	expect.Equal("treatment", w.Header().Get("X-Suggestions-Variant"))
+	var record audit.Record
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	expect.Equal("no-blacklist", record.Experiment)
+	expect.Equal("treatment", record.Variant)
+}
+
+func TestRequestHandler_HandleSuggestionInForcedVariant(t *testing.T) {
+	expect := assert.New(t)
+	body := []byte(`{"id":"http://www.ft.com/thing/content","title":"Test title"}`)
+	suggestion := service.Suggestion{Concept: service.Concept{ID: "authors-suggestion-api", PrefLabel: "prefLabel2", Type: personType}}
+	experiment, err := service.NewExperiment("no-blacklist", service.Variant{Name: "control", Weight: 1}, service.Variant{Name: "treatment", Weight: 0, SkipFilters: []string{service.FilterBlacklist}})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/content/suggest", bytes.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_test")
+	req.Header.Add("X-Suggestions-Variant", "treatment")
+	w := httptest.NewRecorder()
+	handler, _ := newSingleSuggesterHandler(t, body, suggestion)
+	handler.suggester.Experiment = experiment
+	handler.HandleSuggestion(w, req)
+
+	expect.Equal(http.StatusOK, w.Code)
+	expect.Equal("treatment", w.Header().Get("X-Suggestions-Variant"), "the requested variant is used even without traffic")
+
+	req = httptest.NewRequest("POST", "/content/suggest", bytes.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_test")
+	req.Header.Add("X-Suggestions-Variant", "unknown")
+	w = httptest.NewRecorder()
+	handler, mockSuggester := newSingleSuggesterHandler(t, body, suggestion)
+	handler.suggester.Experiment = experiment
+	handler.HandleSuggestion(w, req)
+
+	expect.Equal(http.StatusBadRequest, w.Code)
+	expect.JSONEq(`{"message":"The X-Suggestions-Variant header is not a variant of the experiment"}`, w.Body.String())
+	mockSuggester.AssertNotCalled(t, "GetSuggestions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// contentReaderFunc is a service.ContentReader calling itself.
+type contentReaderFunc func(ctx context.Context, uuid, tid, origin string) ([]byte, error)
+
+func (f contentReaderFunc) GetContent(ctx context.Context, uuid, tid, origin string) ([]byte, error) {
+	return f(ctx, uuid, tid, origin)
+}
+
+func TestRequestHandler_HandleStoredContentSuggestion(t *testing.T) {
+	expect := assert.New(t)
+
+	body := []byte(`{"id":"http://www.ft.com/thing/` + contentUUID + `","title":"Test title"}`)
+	suggestion := service.Suggestion{Concept: service.Concept{ID: "authors-suggestion-api", PrefLabel: "prefLabel2", Type: personType}}
+	handler, mockSuggester := newSingleSuggesterHandler(t, body, suggestion)
+	handler.ContentReader = contentReaderFunc(func(_ context.Context, uuid, tid, origin string) ([]byte, error) {
+		expect.Equal(contentUUID, uuid)
+		expect.Equal("tid_test", tid)
+		expect.Equal("tests_origin", origin)
+		return body, nil
+	})
+	router := mux.NewRouter()
+	router.HandleFunc("/content/{uuid}/suggest", handler.HandleStoredContentSuggestion).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/content/"+contentUUID+"/suggest", nil)
+	req.Header.Add("X-Request-Id", "tid_test")
+	reqorigin.SetHeader(req, "tests_origin")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	expect.Equal(http.StatusOK, w.Code)
+	var response service.SuggestionsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	expect.Equal([]service.Suggestion{suggestion}, response.Suggestions)
+	mockSuggester.AssertExpectations(t)
+}
+
+func TestRequestHandler_HandleStoredContentSuggestionErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		uuid    string
+		content []byte
+		err     error
+		status  int
+		message string
+	}{
+		{name: "invalid uuid", uuid: "not-a-uuid", status: http.StatusBadRequest, message: `"not-a-uuid" is not a valid content UUID`},
+		{name: "not found", uuid: contentUUID, err: service.ContentNotFoundError, status: http.StatusNotFound, message: "Content " + contentUUID + " not found"},
+		{name: "read error", uuid: contentUUID, err: errors.New("connection refused"), status: http.StatusServiceUnavailable, message: "Reading the content failed"},
+		{name: "invalid content", uuid: contentUUID, content: []byte(`[]`), status: http.StatusBadGateway, message: "The stored content is not a non-empty JSON object"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewRequestHandler(nil, logger.NewUPPLogger("test-logger", "panic"))
+			handler.ContentReader = contentReaderFunc(func(context.Context, string, string, string) ([]byte, error) {
+				return tc.content, tc.err
+			})
+			router := mux.NewRouter()
+			router.HandleFunc("/content/{uuid}/suggest", handler.HandleStoredContentSuggestion).Methods(http.MethodGet)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/content/"+tc.uuid+"/suggest", nil))
+			assert.Equal(t, tc.status, w.Code)
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tc.message, body["message"])
+		})
+	}
+}
+
+// newSingleSuggesterHandler creates a RequestHandler aggregating a single mocked suggester that returns the suggestion,
+// with working concordance, broader concepts and blacklist mocks.
+func newSingleSuggesterHandler(t *testing.T, body []byte, suggestion service.Suggestion) (*RequestHandler, *mockSuggesterService) {
+	log := logger.NewUPPLogger("test-logger", "panic")
+
+	mockClient := new(mockHttpClient)
+	concordanceBody, err := json.Marshal(service.ConcordanceResponse{Concepts: map[string]service.Concept{suggestion.ID: suggestion.Concept}})
+	require.NoError(t, err)
+	mockClient.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{Body: ioutil.NopCloser(bytes.NewReader(concordanceBody)), StatusCode: http.StatusOK}, nil)
+	mockConcordance := &service.ConcordanceService{ConcordanceBaseURL: "concordanceBaseURL", ConcordanceEndpoint: "concordanceEndpoint", Client: mockClient}
+
+	mockPublicThings := new(mockHttpClient)
+	mockPublicThings.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{Body: ioutil.NopCloser(strings.NewReader(`{"things":{}}`)), StatusCode: http.StatusOK}, nil)
+	broaderService := &service.BroaderConceptsProvider{Client: mockPublicThings}
+
+	blacklisterMock := new(mockHttpClient)
+	blacklisterMock.On("Do", mock.AnythingOfType("*http.Request")).Return(&http.Response{Body: ioutil.NopCloser(strings.NewReader(`{"uuids":[]}`)), StatusCode: http.StatusOK}, nil)
+	blacklister := service.NewConceptBlacklister("blacklisterUrl", "blacklisterEndpoint", blacklisterMock)
+
+	mockSuggester := new(mockSuggesterService)
+	mockSuggester.On("GetSuggestions", body, "tid_test", mock.AnythingOfType("string")).Return(service.SuggestionsResponse{Suggestions: []service.Suggestion{suggestion}}, nil).Once()
+	mockSuggester.On("FilterSuggestions", []service.Suggestion{suggestion}).Return([]service.Suggestion{suggestion}).Once()
+
+	return NewRequestHandler(service.NewAggregateSuggester(log, mockConcordance, broaderService, blacklister, mockSuggester), log), mockSuggester
+}
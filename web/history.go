@@ -0,0 +1,53 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/history"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/gorilla/mux"
+)
+
+const HistoryPath = "/content/{uuid}/suggestions/history"
+
+// HistoryHandler lists the suggestions returned for a piece of content over time.
+type HistoryHandler struct {
+	store history.Store
+	log   *logger.UPPLogger
+}
+
+func NewHistoryHandler(store history.Store, log *logger.UPPLogger) *HistoryHandler {
+	return &HistoryHandler{
+		store: store,
+		log:   log,
+	}
+}
+
+type historyResponse struct {
+	UUID     string            `json:"uuid"`
+	Versions []history.Version `json:"versions"`
+}
+
+// HandleHistory responds with the stored versions of the content, oldest first, each diffed with the previous one.
+func (h *HistoryHandler) HandleHistory(resp http.ResponseWriter, req *http.Request) {
+	uuid := mux.Vars(req)["uuid"]
+	if !uuidRegexp.MatchString(uuid) {
+		writeMessage(resp, http.StatusBadRequest, fmt.Sprintf("%q is not a valid content UUID", uuid))
+		return
+	}
+	entries, err := h.store.List(uuid)
+	if err != nil {
+		h.log.WithTransactionID(tidutils.GetTransactionIDFromRequest(req)).WithError(err).Error("Could not read the suggestions history")
+		writeMessage(resp, http.StatusInternalServerError, "Could not read the suggestions history")
+		return
+	}
+	if len(entries) == 0 {
+		writeMessage(resp, http.StatusNotFound, fmt.Sprintf("No suggestions history for content %s", uuid))
+		return
+	}
+	jsonResponse, _ := json.Marshal(historyResponse{UUID: uuid, Versions: history.Versions(entries)})
+	writeResponse(resp, http.StatusOK, jsonResponse)
+}
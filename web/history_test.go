@@ -0,0 +1,92 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/history"
+	"github.com/Financial-Times/public-suggestions-api/reqorigin"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHistoryRouter(t *testing.T) (*mux.Router, history.Store) {
+	store, err := history.OpenBoltStore(filepath.Join(t.TempDir(), "history.db"), 10)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	router := mux.NewRouter()
+	router.HandleFunc(HistoryPath, NewHistoryHandler(store, logger.NewUPPLogger("test-logger", "panic")).HandleHistory).Methods(http.MethodGet)
+	return router, store
+}
+
+func TestRequestHandler_HandleSuggestionRecordsHistory(t *testing.T) {
+	_, store := newHistoryRouter(t)
+
+	body := []byte(`{"id":"http://www.ft.com/thing/` + contentUUID + `","title":"Test title"}`)
+	req := httptest.NewRequest(http.MethodPost, "/content/suggest", bytes.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_test")
+	reqorigin.SetHeader(req, "tests_origin")
+	w := httptest.NewRecorder()
+
+	suggestion := service.Suggestion{Concept: service.Concept{ID: "authors-suggestion-api", PrefLabel: "prefLabel2", Type: personType}}
+	handler, _ := newSingleSuggesterHandler(t, body, suggestion)
+	handler.History = store
+	handler.HandleSuggestion(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	entries, err := store.List(contentUUID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "tid_test", entries[0].TransactionID)
+	assert.Equal(t, "tests_origin", entries[0].Origin)
+	assert.Equal(t, []service.Suggestion{suggestion}, entries[0].Suggestions)
+	assert.Equal(t, []service.SourceReport{{Source: "Mock suggester service", Suggestions: []service.Suggestion{suggestion}, Returned: []service.Suggestion{suggestion}}}, entries[0].Sources)
+	assert.WithinDuration(t, time.Now(), entries[0].Time, time.Minute)
+}
+
+func TestHistoryHandler_HandleHistory(t *testing.T) {
+	router, store := newHistoryRouter(t)
+	person := service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/person"}, Predicate: "http://www.ft.com/ontology/annotation/mentions"}
+	topic := service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/topic"}, Predicate: "http://www.ft.com/ontology/annotation/about"}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Add(history.Entry{Time: now, TransactionID: "tid_1", ContentUUID: contentUUID, Suggestions: []service.Suggestion{person}}))
+	require.NoError(t, store.Add(history.Entry{Time: now.Add(time.Hour), TransactionID: "tid_2", ContentUUID: contentUUID, Suggestions: []service.Suggestion{topic}}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/content/"+contentUUID+"/suggestions/history", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"uuid":"`+contentUUID+`","versions":[
+		{"time":"2026-10-01T12:00:00Z","transactionId":"tid_1","contentUuid":"`+contentUUID+`","suggestions":[{"id":"http://www.ft.com/thing/person","predicate":"http://www.ft.com/ontology/annotation/mentions"}],"sources":null},
+		{"time":"2026-10-01T13:00:00Z","transactionId":"tid_2","contentUuid":"`+contentUUID+`","suggestions":[{"id":"http://www.ft.com/thing/topic","predicate":"http://www.ft.com/ontology/annotation/about"}],"sources":null,
+		 "diff":{"added":[{"id":"http://www.ft.com/thing/topic","predicate":"http://www.ft.com/ontology/annotation/about"}],"removed":[{"id":"http://www.ft.com/thing/person","predicate":"http://www.ft.com/ontology/annotation/mentions"}]}}
+	]}`, w.Body.String())
+}
+
+func TestHistoryHandler_HandleHistoryErrors(t *testing.T) {
+	router, _ := newHistoryRouter(t)
+	testCases := []struct {
+		uuid    string
+		status  int
+		message string
+	}{
+		{uuid: "not-a-uuid", status: http.StatusBadRequest, message: `"not-a-uuid" is not a valid content UUID`},
+		{uuid: contentUUID, status: http.StatusNotFound, message: "No suggestions history for content " + contentUUID},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/content/"+tc.uuid+"/suggestions/history", nil))
+		assert.Equal(t, tc.status, w.Code)
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, tc.message, body["message"])
+	}
+}
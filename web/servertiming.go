@@ -0,0 +1,24 @@
+package web
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Financial-Times/public-suggestions-api/service"
+)
+
+const serverTimingHeader = "Server-Timing"
+
+// serverTiming formats the stages as a Server-Timing header value, see https://www.w3.org/TR/server-timing/
+func serverTiming(stages []service.StageTiming) string {
+	metrics := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		metric := stage.Name + ";dur=" + strconv.FormatFloat(stage.DurationMs, 'f', -1, 64)
+		if stage.Description != "" {
+			metric += fmt.Sprintf(";desc=%q", stage.Description)
+		}
+		metrics = append(metrics, metric)
+	}
+	return strings.Join(metrics, ", ")
+}
@@ -0,0 +1,20 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerTiming(t *testing.T) {
+	header := serverTiming([]service.StageTiming{
+		{Name: "suggest.ontotext-suggestion-api", Description: "Ontotext Suggestion API", DurationMs: 12.5},
+		{Name: "broader", DurationMs: 3},
+	})
+	assert.Equal(t, `suggest.ontotext-suggestion-api;dur=12.5;desc="Ontotext Suggestion API", broader;dur=3`, header)
+}
+
+func TestServerTimingEmpty(t *testing.T) {
+	assert.Equal(t, "", serverTiming(nil))
+}
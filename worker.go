@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/worker"
+	cli "github.com/jawher/mow.cli"
+)
+
+const workerDescription = "Suggest annotations for a stream of content events and publish them. The downstream services are configured by the application options"
+
+func workerCommand(cmd *cli.Cmd, log *logger.UPPLogger, newSuggester func() worker.Suggester) {
+	cmd.Spec = "[--input] [--output] [--dead-letters] [--max-attempts] [--retry-backoff] [--dedup-size]"
+
+	input := cmd.String(cli.StringOpt{
+		Name:  "input",
+		Value: "-",
+		Desc:  "NDJSON file with one content payload per line, - for stdin",
+	})
+	output := cmd.String(cli.StringOpt{
+		Name:  "output",
+		Value: "-",
+		Desc:  "NDJSON file the suggestions are appended to, - for stdout",
+	})
+	deadLetters := cmd.String(cli.StringOpt{
+		Name:  "dead-letters",
+		Value: "dead-letters.ndjson",
+		Desc:  "NDJSON file the content that could not be processed is appended to",
+	})
+	maxAttempts := cmd.Int(cli.IntOpt{
+		Name:  "max-attempts",
+		Value: 3,
+		Desc:  "Number of times the suggestions of a content are requested before it is dead-lettered",
+	})
+	retryBackoff := cmd.String(cli.StringOpt{
+		Name:  "retry-backoff",
+		Value: "1s",
+		Desc:  "Wait before the second attempt, doubled with every attempt",
+	})
+	dedupSize := cmd.Int(cli.IntOpt{
+		Name:  "dedup-size",
+		Value: 10000,
+		Desc:  "Number of publish references remembered to skip the duplicate events",
+	})
+
+	cmd.Action = func() {
+		if *output == "-" {
+			// keep stdout for the suggestions
+			log.Out = os.Stderr
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		if err := runWorker(ctx, newSuggester(), log, *input, *output, *deadLetters, *maxAttempts, *retryBackoff, *dedupSize); err != nil {
+			fmt.Fprintf(os.Stderr, "worker failed: %v\n", err)
+			cli.Exit(1)
+		}
+	}
+}
+
+func runWorker(ctx context.Context, suggester worker.Suggester, log *logger.UPPLogger, input, output, deadLetters string, maxAttempts int, retryBackoff string, dedupSize int) error {
+	backoff, err := time.ParseDuration(retryBackoff)
+	if err != nil {
+		return fmt.Errorf("invalid retry backoff: %w", err)
+	}
+
+	in := io.Reader(os.Stdin)
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	out := io.Writer(os.Stdout)
+	if output != "-" {
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	deadLettersFile, err := os.OpenFile(deadLetters, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer deadLettersFile.Close()
+
+	w, err := worker.New(suggester, worker.NewNDJSONConsumer(in), worker.NewNDJSONProducer(out), worker.NewNDJSONProducer(deadLettersFile), worker.Config{
+		MaxAttempts:  maxAttempts,
+		RetryBackoff: backoff,
+		DedupSize:    dedupSize,
+	}, log)
+	if err != nil {
+		return err
+	}
+	stats, err := w.Run(ctx)
+	log.WithField("processed", stats.Processed).
+		WithField("duplicates", stats.Duplicates).
+		WithField("dead_lettered", stats.DeadLettered).
+		Info("Worker stopped")
+	return err
+}
@@ -0,0 +1,39 @@
+package worker
+
+import "sync"
+
+// dedup remembers the last publish references that were processed.
+type dedup struct {
+	mu   sync.Mutex
+	seen map[string]bool
+	ring []string
+	next int
+}
+
+func newDedup(size int) *dedup {
+	return &dedup{
+		seen: make(map[string]bool, size),
+		ring: make([]string, size),
+	}
+}
+
+func (d *dedup) contains(ref string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.seen[ref]
+}
+
+// add remembers ref, forgetting the oldest publish reference when full.
+func (d *dedup) add(ref string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.ring) == 0 || d.seen[ref] {
+		return
+	}
+	if old := d.ring[d.next]; old != "" {
+		delete(d.seen, old)
+	}
+	d.ring[d.next] = ref
+	d.seen[ref] = true
+	d.next = (d.next + 1) % len(d.ring)
+}
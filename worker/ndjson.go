@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// NDJSONConsumer reads one message body per line, e.g. a file of content payloads. Blank lines are skipped.
+// The messages given back by Nack are delivered again before the next line.
+type NDJSONConsumer struct {
+	mu      sync.Mutex
+	reader  *bufio.Reader
+	line    int
+	pending []Message
+}
+
+func NewNDJSONConsumer(r io.Reader) *NDJSONConsumer {
+	return &NDJSONConsumer{reader: bufio.NewReader(r)}
+}
+
+// Receive reads the next line, the message ID is its line number.
+func (c *NDJSONConsumer) Receive(ctx context.Context) (Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return Message{}, err
+	}
+	if len(c.pending) > 0 {
+		m := c.pending[0]
+		c.pending = c.pending[1:]
+		return m, nil
+	}
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			c.line++
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return Message{ID: strconv.Itoa(c.line), Body: line}, nil
+		}
+		if err != nil {
+			return Message{}, err
+		}
+	}
+}
+
+// Ack does nothing, the lines are read once.
+func (c *NDJSONConsumer) Ack(_ context.Context, _ Message) error {
+	return nil
+}
+
+func (c *NDJSONConsumer) Nack(_ context.Context, m Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, m)
+	return nil
+}
+
+// NDJSONProducer writes the JSON body of every message on its own line, the headers are not written.
+type NDJSONProducer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewNDJSONProducer(w io.Writer) *NDJSONProducer {
+	return &NDJSONProducer{w: w}
+}
+
+func (p *NDJSONProducer) Send(_ context.Context, m Message) error {
+	line := &bytes.Buffer{}
+	if err := json.Compact(line, m.Body); err != nil {
+		return fmt.Errorf("message %s is not JSON: %w", m.ID, err)
+	}
+	line.WriteByte('\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.w.Write(line.Bytes())
+	return err
+}
@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// Message is a content event read from a Consumer, or a result written to a Producer.
+type Message struct {
+	// ID identifies the message within its Consumer, to acknowledge it.
+	ID      string
+	Headers map[string]string
+	Body    []byte
+}
+
+// Consumer delivers the messages at least once: a message that is not acknowledged is delivered again.
+type Consumer interface {
+	// Receive blocks until a message is available. It returns io.EOF when the input is exhausted.
+	Receive(ctx context.Context) (Message, error)
+	// Ack marks the message as processed, it will not be delivered again.
+	Ack(ctx context.Context, m Message) error
+	// Nack gives the message back to be delivered again.
+	Nack(ctx context.Context, m Message) error
+}
+
+// Producer publishes messages.
+type Producer interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// MemoryTransport is an in-memory queue, it is both a Consumer and a Producer.
+type MemoryTransport struct {
+	mu       sync.Mutex
+	queue    []Message
+	inFlight map[string]Message
+	acked    []Message
+	closed   bool
+	nextID   int
+	notify   chan struct{}
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{
+		inFlight: map[string]Message{},
+		notify:   make(chan struct{}),
+	}
+}
+
+// Send queues the message, it is given an ID when it has none.
+func (t *MemoryTransport) Send(_ context.Context, m Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if m.ID == "" {
+		t.nextID++
+		m.ID = strconv.Itoa(t.nextID)
+	}
+	t.queue = append(t.queue, m)
+	t.wake()
+	return nil
+}
+
+// Close stops the transport from waiting for more messages, Receive returns io.EOF once the queue is empty.
+func (t *MemoryTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	t.wake()
+	return nil
+}
+
+func (t *MemoryTransport) Receive(ctx context.Context) (Message, error) {
+	for {
+		t.mu.Lock()
+		if len(t.queue) > 0 {
+			m := t.queue[0]
+			t.queue = t.queue[1:]
+			t.inFlight[m.ID] = m
+			t.mu.Unlock()
+			return m, nil
+		}
+		if t.closed && len(t.inFlight) == 0 {
+			t.mu.Unlock()
+			return Message{}, io.EOF
+		}
+		notify := t.notify
+		t.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+func (t *MemoryTransport) Ack(_ context.Context, m Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if m, ok := t.inFlight[m.ID]; ok {
+		delete(t.inFlight, m.ID)
+		t.acked = append(t.acked, m)
+		t.wake()
+	}
+	return nil
+}
+
+func (t *MemoryTransport) Nack(_ context.Context, m Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if m, ok := t.inFlight[m.ID]; ok {
+		delete(t.inFlight, m.ID)
+		t.queue = append([]Message{m}, t.queue...)
+		t.wake()
+	}
+	return nil
+}
+
+// Pending returns the queued messages, e.g. the results sent to an output transport.
+func (t *MemoryTransport) Pending() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pending := make([]Message, len(t.queue))
+	copy(pending, t.queue)
+	return pending
+}
+
+// Acked returns the acknowledged messages, in acknowledgement order.
+func (t *MemoryTransport) Acked() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	acked := make([]Message, len(t.acked))
+	copy(acked, t.acked)
+	return acked
+}
+
+// wake unblocks the pending Receive calls, t.mu must be held.
+func (t *MemoryTransport) wake() {
+	close(t.notify)
+	t.notify = make(chan struct{})
+}
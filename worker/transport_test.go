@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTransport(t *testing.T) {
+	ctx := context.Background()
+	transport := NewMemoryTransport()
+	require.NoError(t, transport.Send(ctx, Message{Body: []byte("a")}))
+	require.NoError(t, transport.Send(ctx, Message{Body: []byte("b")}))
+
+	a, err := transport.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1", a.ID)
+	require.NoError(t, transport.Nack(ctx, a))
+	again, err := transport.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, a, again, "a message given back is delivered again first")
+	require.NoError(t, transport.Ack(ctx, again))
+
+	b, err := transport.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(b.Body))
+	require.NoError(t, transport.Close())
+
+	received := make(chan error)
+	go func() {
+		_, err := transport.Receive(ctx)
+		received <- err
+	}()
+	select {
+	case <-received:
+		t.Fatal("Receive should wait for the in flight message")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.NoError(t, transport.Ack(ctx, b))
+	assert.Equal(t, io.EOF, <-received)
+	assert.Equal(t, []Message{a, b}, transport.Acked())
+	assert.Empty(t, transport.Pending())
+}
+
+func TestMemoryTransport_ReceiveCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewMemoryTransport().Receive(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestNDJSONConsumer(t *testing.T) {
+	ctx := context.Background()
+	consumer := NewNDJSONConsumer(strings.NewReader("{\"id\":\"a\"}\n\n  \n{\"id\":\"b\"}"))
+
+	a, err := consumer.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Message{ID: "1", Body: []byte(`{"id":"a"}`)}, a)
+	require.NoError(t, consumer.Nack(ctx, a))
+	again, err := consumer.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, a, again)
+	require.NoError(t, consumer.Ack(ctx, again))
+
+	b, err := consumer.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Message{ID: "4", Body: []byte(`{"id":"b"}`)}, b)
+	_, err = consumer.Receive(ctx)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestNDJSONProducer(t *testing.T) {
+	out := &bytes.Buffer{}
+	producer := NewNDJSONProducer(out)
+	require.NoError(t, producer.Send(context.Background(), Message{ID: "1", Body: []byte("{\n  \"id\": \"a\"\n}")}))
+	assert.Error(t, producer.Send(context.Background(), Message{ID: "2", Body: []byte("not json")}))
+	assert.Equal(t, "{\"id\":\"a\"}\n", out.String())
+}
@@ -0,0 +1,254 @@
+// Package worker suggests annotations for the content events of a message transport and publishes them.
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/reqorigin"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+)
+
+const (
+	Origin              = "public-suggestions-api-worker"
+	TransactionIDHeader = "X-Request-Id"
+)
+
+// Suggester is implemented by service.AggregateSuggester.
+type Suggester interface {
+	GetSuggestions(ctx context.Context, payload []byte, tid, origin string) (service.SuggestionsResponse, error)
+}
+
+// Config sets how the failures and the duplicates are handled.
+type Config struct {
+	// MaxAttempts is the number of times the suggestions are requested before the message is dead-lettered.
+	MaxAttempts int
+	// RetryBackoff is the wait before the second attempt, it doubles with every attempt.
+	RetryBackoff time.Duration
+	// DedupSize is the number of publish references remembered to skip the duplicate messages.
+	DedupSize int
+}
+
+// Result is the message published for every processed content.
+type Result struct {
+	UUID             string               `json:"uuid,omitempty"`
+	PublishReference string               `json:"publishReference,omitempty"`
+	Suggestions      []service.Suggestion `json:"suggestions"`
+}
+
+// DeadLetter is the message published for the content that could not be processed.
+type DeadLetter struct {
+	Error            string            `json:"error"`
+	Attempts         int               `json:"attempts"`
+	MessageID        string            `json:"messageId"`
+	PublishReference string            `json:"publishReference,omitempty"`
+	Headers          map[string]string `json:"headers,omitempty"`
+	// Body is the original message when it is JSON, RawBody otherwise.
+	Body    json.RawMessage `json:"body,omitempty"`
+	RawBody string          `json:"rawBody,omitempty"`
+}
+
+// Stats counts what the worker did with the messages.
+type Stats struct {
+	Processed    int `json:"processed"`
+	Duplicates   int `json:"duplicates"`
+	DeadLettered int `json:"deadLettered"`
+}
+
+// Worker requests the suggestions of every message of the input and publishes them to the output.
+// A message is acknowledged once its result or its dead letter is published, so it is processed at least once.
+type Worker struct {
+	suggester   Suggester
+	input       Consumer
+	output      Producer
+	deadLetters Producer
+	config      Config
+	log         *logger.UPPLogger
+	dedup       *dedup
+	sleep       func(ctx context.Context, d time.Duration) error
+}
+
+func New(suggester Suggester, input Consumer, output, deadLetters Producer, config Config, log *logger.UPPLogger) (*Worker, error) {
+	if config.MaxAttempts < 1 {
+		return nil, fmt.Errorf("max attempts must be at least 1, got %d", config.MaxAttempts)
+	}
+	if config.RetryBackoff < 0 {
+		return nil, fmt.Errorf("retry backoff must not be negative, got %v", config.RetryBackoff)
+	}
+	if config.DedupSize < 0 {
+		return nil, fmt.Errorf("dedup size must not be negative, got %d", config.DedupSize)
+	}
+	return &Worker{
+		suggester:   suggester,
+		input:       input,
+		output:      output,
+		deadLetters: deadLetters,
+		config:      config,
+		log:         log,
+		dedup:       newDedup(config.DedupSize),
+		sleep:       sleep,
+	}, nil
+}
+
+// Run processes the messages until the input is exhausted or the context is done, both are a clean stop.
+// It stops on the first message that can be neither published nor dead-lettered, after giving it back to the input,
+// as it does with the message in flight when the context is done.
+func (w *Worker) Run(ctx context.Context) (Stats, error) {
+	var stats Stats
+	for {
+		m, err := w.input.Receive(ctx)
+		if errors.Is(err, io.EOF) || stopped(ctx, err) {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+		if err := w.process(ctx, m, &stats); err != nil {
+			if nackErr := w.input.Nack(context.WithoutCancel(ctx), m); nackErr != nil {
+				w.log.WithError(nackErr).Errorf("Could not give message %s back to the input", m.ID)
+			}
+			if stopped(ctx, err) {
+				return stats, nil
+			}
+			return stats, err
+		}
+		if err := w.input.Ack(ctx, m); err != nil {
+			return stats, fmt.Errorf("could not acknowledge message %s: %w", m.ID, err)
+		}
+	}
+}
+
+// stopped tells if the error is the one of the context being done, e.g. on shutdown.
+func stopped(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err())
+}
+
+func (w *Worker) process(ctx context.Context, m Message, stats *Stats) error {
+	content, err := parseContent(m.Body)
+	if err != nil {
+		return w.deadLetter(ctx, m, "", err, 0, stats)
+	}
+
+	ref := content.PublishReference
+	if ref == "" {
+		ref = m.Headers[TransactionIDHeader]
+	}
+	tid := ref
+	if tid == "" {
+		tid = tidutils.NewTransactionID()
+	}
+	logEntry := w.log.WithTransactionID(tid).WithField("message_id", m.ID)
+	if ref != "" && w.dedup.contains(ref) {
+		logEntry.Info("Skipping the duplicate of a processed message")
+		stats.Duplicates++
+		return nil
+	}
+
+	origin := m.Headers[reqorigin.OriginKey]
+	if origin == "" {
+		origin = Origin
+	}
+	var suggestions service.SuggestionsResponse
+	attempt := 0
+	for {
+		attempt++
+		suggestions, err = w.suggest(ctx, m.Body, tid, origin)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= w.config.MaxAttempts {
+			return w.deadLetter(ctx, m, ref, err, attempt, stats)
+		}
+		backoff := w.config.RetryBackoff << (attempt - 1)
+		logEntry.WithError(err).Warnf("Aggregating suggestions failed, retrying in %v", backoff)
+		if err := w.sleep(ctx, backoff); err != nil {
+			return err
+		}
+	}
+
+	body, _ := json.Marshal(Result{UUID: service.ContentUUID(m.Body), PublishReference: ref, Suggestions: suggestions.Suggestions})
+	if err := w.output.Send(ctx, Message{ID: m.ID, Headers: map[string]string{TransactionIDHeader: tid}, Body: body}); err != nil {
+		return fmt.Errorf("could not publish the suggestions of message %s: %w", m.ID, err)
+	}
+	if ref != "" {
+		w.dedup.add(ref)
+	}
+	stats.Processed++
+	return nil
+}
+
+// suggest requests the suggestions of the content. The AggregateSuggester answers with the suggestions of the other
+// Suggesters when one fails, so a failed Suggester fails the attempt rather than publishing partial suggestions.
+func (w *Worker) suggest(ctx context.Context, body []byte, tid, origin string) (service.SuggestionsResponse, error) {
+	report := &service.Report{}
+	suggestions, err := w.suggester.GetSuggestions(service.ContextWithReport(ctx, report), body, tid, origin)
+	if err != nil {
+		return suggestions, err
+	}
+	for _, source := range report.Sources() {
+		if source.Failed() {
+			return suggestions, fmt.Errorf("%s failed: %s", source.Source, source.Error)
+		}
+	}
+	return suggestions, nil
+}
+
+func (w *Worker) deadLetter(ctx context.Context, m Message, ref string, cause error, attempts int, stats *Stats) error {
+	w.log.WithError(cause).WithField("message_id", m.ID).WithField("attempts", attempts).Error("Dead-lettering message")
+	letter := DeadLetter{
+		Error:            cause.Error(),
+		Attempts:         attempts,
+		MessageID:        m.ID,
+		PublishReference: ref,
+		Headers:          m.Headers,
+	}
+	if json.Valid(m.Body) {
+		letter.Body = m.Body
+	} else {
+		letter.RawBody = string(m.Body)
+	}
+	body, _ := json.Marshal(letter)
+	if err := w.deadLetters.Send(ctx, Message{ID: m.ID, Headers: m.Headers, Body: body}); err != nil {
+		return fmt.Errorf("could not dead-letter message %s: %w", m.ID, err)
+	}
+	stats.DeadLettered++
+	return nil
+}
+
+type content struct {
+	PublishReference string `json:"publishReference"`
+}
+
+// parseContent checks the body is a non-empty JSON object, as the payloads of /content/suggest.
+func parseContent(body []byte) (content, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return content{}, fmt.Errorf("message is not a JSON object: %w", err)
+	}
+	if len(fields) == 0 {
+		return content{}, errors.New("message is an empty JSON object")
+	}
+	var c content
+	json.Unmarshal(body, &c)
+	return c, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
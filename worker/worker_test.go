@@ -0,0 +1,264 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedSuggester fails the first failures calls of every transaction, then suggests the suggestion.
+type scriptedSuggester struct {
+	mu         sync.Mutex
+	failures   int
+	suggestion service.Suggestion
+	calls      map[string]int
+	origins    []string
+}
+
+func (s *scriptedSuggester) GetSuggestions(_ context.Context, _ []byte, tid, origin string) (service.SuggestionsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.calls == nil {
+		s.calls = map[string]int{}
+	}
+	s.calls[tid]++
+	s.origins = append(s.origins, origin)
+	if s.calls[tid] <= s.failures {
+		return service.SuggestionsResponse{}, errors.New("concordance unavailable")
+	}
+	return service.SuggestionsResponse{Suggestions: []service.Suggestion{s.suggestion}}, nil
+}
+
+type failingProducer struct{}
+
+func (failingProducer) Send(context.Context, Message) error {
+	return errors.New("broker unavailable")
+}
+
+func newTestWorker(t *testing.T, suggester Suggester, output Producer, config Config, messages ...Message) (*Worker, *MemoryTransport, *MemoryTransport) {
+	input := NewMemoryTransport()
+	for _, m := range messages {
+		require.NoError(t, input.Send(context.Background(), m))
+	}
+	require.NoError(t, input.Close())
+	deadLetters := NewMemoryTransport()
+	w, err := New(suggester, input, output, deadLetters, config, logger.NewUPPLogger("test-worker", "panic"))
+	require.NoError(t, err)
+	return w, input, deadLetters
+}
+
+func TestWorker_Run(t *testing.T) {
+	suggestion := service.Suggestion{Concept: service.Concept{ID: "http://www.ft.com/thing/person"}}
+	suggester := &scriptedSuggester{suggestion: suggestion}
+	output := NewMemoryTransport()
+	w, input, deadLetters := newTestWorker(t, suggester, output, Config{MaxAttempts: 1, DedupSize: 10},
+		Message{Body: []byte(`{"uuid":"f758ef56-c40a-3162-91aa-3e8a3aabc495","publishReference":"tid_publish"}`), Headers: map[string]string{"X-Origin": "methode"}},
+		Message{Body: []byte(`{"uuid":"f758ef56-c40a-3162-91aa-3e8a3aabc495","publishReference":"tid_publish"}`)},
+		Message{Body: []byte(`{"uuid":"f758ef56-c40a-3162-91aa-3e8a3aabc495"}`), Headers: map[string]string{"X-Request-Id": "tid_header"}},
+	)
+
+	stats, err := w.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Processed: 2, Duplicates: 1}, stats)
+	assert.Len(t, input.Acked(), 3)
+	assert.Empty(t, deadLetters.Pending())
+	assert.Equal(t, []string{"methode", Origin}, suggester.origins)
+
+	results := output.Pending()
+	require.Len(t, results, 2)
+	assert.Equal(t, "tid_publish", results[0].Headers["X-Request-Id"])
+	var result Result
+	require.NoError(t, json.Unmarshal(results[0].Body, &result))
+	assert.Equal(t, Result{UUID: "f758ef56-c40a-3162-91aa-3e8a3aabc495", PublishReference: "tid_publish", Suggestions: []service.Suggestion{suggestion}}, result)
+	require.NoError(t, json.Unmarshal(results[1].Body, &result))
+	assert.Equal(t, "tid_header", result.PublishReference, "the transaction ID header is the publish reference of the content without one")
+}
+
+func TestWorker_RunRetries(t *testing.T) {
+	suggester := &scriptedSuggester{failures: 2}
+	output := NewMemoryTransport()
+	w, _, deadLetters := newTestWorker(t, suggester, output, Config{MaxAttempts: 3, RetryBackoff: time.Second},
+		Message{Body: []byte(`{"publishReference":"tid_publish"}`)})
+	var backoffs []time.Duration
+	w.sleep = func(_ context.Context, d time.Duration) error {
+		backoffs = append(backoffs, d)
+		return nil
+	}
+
+	stats, err := w.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Processed: 1}, stats)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, backoffs)
+	assert.Len(t, output.Pending(), 1)
+	assert.Empty(t, deadLetters.Pending())
+}
+
+func TestWorker_RunDeadLetters(t *testing.T) {
+	suggester := &scriptedSuggester{failures: 5}
+	output := NewMemoryTransport()
+	w, input, deadLetters := newTestWorker(t, suggester, output, Config{MaxAttempts: 2},
+		Message{Body: []byte(`{"publishReference":"tid_publish"}`)},
+		Message{Body: []byte(`not json`)},
+		Message{Body: []byte(`{}`)},
+	)
+
+	stats, err := w.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{DeadLettered: 3}, stats)
+	assert.Len(t, input.Acked(), 3)
+	assert.Empty(t, output.Pending())
+
+	letters := deadLetters.Pending()
+	require.Len(t, letters, 3)
+	var letter DeadLetter
+	require.NoError(t, json.Unmarshal(letters[0].Body, &letter))
+	assert.Equal(t, DeadLetter{Error: "concordance unavailable", Attempts: 2, MessageID: "1", PublishReference: "tid_publish", Body: json.RawMessage(`{"publishReference":"tid_publish"}`)}, letter)
+	letter = DeadLetter{}
+	require.NoError(t, json.Unmarshal(letters[1].Body, &letter))
+	assert.Equal(t, "not json", letter.RawBody)
+	assert.Zero(t, letter.Attempts)
+	letter = DeadLetter{}
+	require.NoError(t, json.Unmarshal(letters[2].Body, &letter))
+	assert.Equal(t, "message is an empty JSON object", letter.Error)
+}
+
+// newDownstreams serves the suggestion API, failing its first failures calls, the concordance and the blacklist.
+func newDownstreams(t *testing.T, failures int) *httptest.Server {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/suggest":
+			if int(atomic.AddInt32(&calls, 1)) <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"suggestions":[]}`))
+		case "/concordances":
+			w.Write([]byte(`{"concepts":{}}`))
+		case "/blacklist":
+			w.Write([]byte(`{"uuids":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestAggregateSuggester(downstreams *httptest.Server) *service.AggregateSuggester {
+	return service.NewAggregateSuggester(logger.NewUPPLogger("test-worker", "panic"),
+		&service.ConcordanceService{ConcordanceBaseURL: downstreams.URL, ConcordanceEndpoint: "/concordances", Client: http.DefaultClient},
+		&service.BroaderConceptsProvider{Client: http.DefaultClient},
+		service.NewConceptBlacklister(downstreams.URL, "/blacklist", http.DefaultClient),
+		service.NewOntotextSuggester(downstreams.URL, "/suggest", http.DefaultClient))
+}
+
+func TestWorker_RunRetriesFailedSuggester(t *testing.T) {
+	output := NewMemoryTransport()
+	w, _, deadLetters := newTestWorker(t, newTestAggregateSuggester(newDownstreams(t, 1)), output, Config{MaxAttempts: 2},
+		Message{Body: []byte(`{"publishReference":"tid_publish","bodyXML":"text"}`)})
+	retries := 0
+	w.sleep = func(context.Context, time.Duration) error {
+		retries++
+		return nil
+	}
+
+	stats, err := w.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Processed: 1}, stats)
+	assert.Equal(t, 1, retries, "the failed suggester is retried")
+	assert.Len(t, output.Pending(), 1)
+	assert.Empty(t, deadLetters.Pending())
+}
+
+func TestWorker_RunDeadLettersFailedSuggester(t *testing.T) {
+	output := NewMemoryTransport()
+	w, input, deadLetters := newTestWorker(t, newTestAggregateSuggester(newDownstreams(t, 5)), output, Config{MaxAttempts: 2},
+		Message{Body: []byte(`{"publishReference":"tid_publish","bodyXML":"text"}`)})
+	w.sleep = func(context.Context, time.Duration) error { return nil }
+
+	stats, err := w.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{DeadLettered: 1}, stats, "the partial suggestions are not published")
+	assert.Len(t, input.Acked(), 1)
+	assert.Empty(t, output.Pending())
+
+	letters := deadLetters.Pending()
+	require.Len(t, letters, 1)
+	var letter DeadLetter
+	require.NoError(t, json.Unmarshal(letters[0].Body, &letter))
+	assert.Equal(t, "Ontotext Suggestion API failed: Ontotext Suggestion API returned HTTP 503", letter.Error)
+	assert.Equal(t, 2, letter.Attempts)
+}
+
+func TestWorker_RunStopped(t *testing.T) {
+	suggester := &scriptedSuggester{failures: 5}
+	output := NewMemoryTransport()
+	w, input, deadLetters := newTestWorker(t, suggester, output, Config{MaxAttempts: 3, RetryBackoff: time.Second},
+		Message{Body: []byte(`{"publishReference":"tid_publish"}`)})
+	ctx, cancel := context.WithCancel(context.Background())
+	w.sleep = func(ctx context.Context, _ time.Duration) error {
+		// the shutdown signal arrives while the message is in flight
+		cancel()
+		return ctx.Err()
+	}
+
+	stats, err := w.Run(ctx)
+	require.NoError(t, err, "a shutdown is not a failure")
+	assert.Equal(t, Stats{}, stats)
+	assert.Len(t, input.Pending(), 1, "the message in flight is given back to the input")
+	assert.Empty(t, input.Acked())
+	assert.Empty(t, deadLetters.Pending())
+
+	idle := NewMemoryTransport()
+	w, err = New(suggester, idle, output, deadLetters, Config{MaxAttempts: 1}, logger.NewUPPLogger("test-worker", "panic"))
+	require.NoError(t, err)
+	_, err = w.Run(ctx)
+	assert.NoError(t, err, "a shutdown while waiting for messages is not a failure")
+}
+
+func TestWorker_RunOutputFailure(t *testing.T) {
+	w, input, _ := newTestWorker(t, &scriptedSuggester{}, failingProducer{}, Config{MaxAttempts: 1, DedupSize: 10},
+		Message{Body: []byte(`{"publishReference":"tid_publish"}`)})
+
+	_, err := w.Run(context.Background())
+	assert.EqualError(t, err, "could not publish the suggestions of message 1: broker unavailable")
+	assert.Empty(t, input.Acked())
+	assert.Len(t, input.Pending(), 1, "the message is given back to be processed again")
+	assert.False(t, w.dedup.contains("tid_publish"))
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	log := logger.NewUPPLogger("test-worker", "panic")
+	for _, c := range []Config{{}, {MaxAttempts: 1, RetryBackoff: -time.Second}, {MaxAttempts: 1, DedupSize: -1}} {
+		_, err := New(&scriptedSuggester{}, NewMemoryTransport(), NewMemoryTransport(), NewMemoryTransport(), c, log)
+		assert.Error(t, err, "%+v", c)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	d := newDedup(2)
+	d.add("a")
+	d.add("b")
+	d.add("a")
+	assert.True(t, d.contains("a"))
+	d.add("c")
+	assert.False(t, d.contains("a"), "the oldest reference is forgotten")
+	assert.True(t, d.contains("b"))
+	assert.True(t, d.contains("c"))
+
+	empty := newDedup(0)
+	empty.add("a")
+	assert.False(t, empty.contains("a"))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-suggestions-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunWorker(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "events.ndjson")
+	output := filepath.Join(dir, "suggestions.ndjson")
+	deadLetters := filepath.Join(dir, "dead-letters.ndjson")
+	require.NoError(t, ioutil.WriteFile(input, []byte(strings.Join([]string{
+		`{"uuid":"f758ef56-c40a-3162-91aa-3e8a3aabc495","publishReference":"tid_1"}`,
+		`{"uuid":"f758ef56-c40a-3162-91aa-3e8a3aabc495","publishReference":"tid_1"}`,
+		`not json`,
+	}, "\n")), 0644))
+	suggester := staticSuggester{Suggestions: []service.Suggestion{{Concept: service.Concept{ID: "http://www.ft.com/thing/1"}}}}
+	log := logger.NewUPPLogger("test", "panic")
+
+	require.NoError(t, runWorker(context.Background(), suggester, log, input, output, deadLetters, 1, "0s", 10))
+	suggestions, err := ioutil.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, `{"uuid":"f758ef56-c40a-3162-91aa-3e8a3aabc495","publishReference":"tid_1","suggestions":[{"id":"http://www.ft.com/thing/1"}]}`+"\n", string(suggestions))
+	letters, err := ioutil.ReadFile(deadLetters)
+	require.NoError(t, err)
+	assert.Contains(t, string(letters), `"rawBody":"not json"`)
+
+	assert.Error(t, runWorker(context.Background(), suggester, log, input, output, deadLetters, 1, "soon", 10))
+}